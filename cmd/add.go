@@ -90,7 +90,7 @@ func init() {
 	addCmd.Flags().StringVar(&apiKey, "apikey", "", "API Token")
 	addCmd.Flags().StringVar(&baseURL, "base-url", "", "Base URL")
 	addCmd.Flags().StringVar(&category, "category", "custom", "Provider category (official/cn_official/aggregator/third_party/custom)")
-	addCmd.Flags().StringVar(&appName, "app", "claude", "Application (claude/codex)")
+	addCmd.Flags().StringVar(&appName, "app", "claude", "Application (claude/codex 或自定义应用)")
 	addCmd.Flags().StringVar(&defaultSonnetModel, "default-sonnet-model", "", "Default Sonnet model (optional, for Claude only)")
 }
 
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var appCmd = &cobra.Command{
+	Use:   "app",
+	Short: "管理受支持的应用",
+	Long: `查看内置应用和自定义应用，切换自定义应用的配置。
+
+自定义应用定义保存在配置目录的 apps/*.json 中，
+声明供应商字段以及它们写入哪些 JSON/TOML/.env 文件。`,
+}
+
+var appListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出所有应用及其 live 配置文件",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		for _, app := range manager.Apps() {
+			kind := "内置"
+			if !config.IsBuiltinApp(app.Name()) {
+				kind = "自定义"
+			}
+			current := "(未设置)"
+			if p := manager.GetCurrentProviderForApp(app.Name()); p != nil {
+				current = p.Name
+			}
+			fmt.Printf("%-10s %-14s [%s] 当前: %s\n", app.Name(), app.DisplayName(), kind, current)
+
+			paths, err := app.LivePaths(manager)
+			if err != nil {
+				return err
+			}
+			for _, path := range paths {
+				fmt.Printf("    %s\n", path)
+			}
+		}
+		fmt.Printf("\n自定义应用目录: %s\n", manager.AppsDir())
+		return nil
+	},
+}
+
+var appSwitchCmd = &cobra.Command{
+	Use:   "switch <应用> <配置名称>",
+	Short: "切换指定应用的配置",
+	Example: `  ccs app switch opencode relay
+  ccs app switch gemini mygemini`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appName, configName := args[0], args[1]
+
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+		app, err := manager.App(appName)
+		if err != nil {
+			return err
+		}
+		provider, err := manager.GetProviderForApp(appName, configName)
+		if err != nil {
+			return fmt.Errorf("获取配置失败: %w", err)
+		}
+
+		if err := manager.SwitchProviderForApp(appName, configName); err != nil {
+			return fmt.Errorf("切换配置失败: %w", err)
+		}
+
+		fmt.Printf("✓ 已切换到 %s 配置: %s\n", app.DisplayName(), configName)
+		if token := app.ExtractToken(provider); token != "" {
+			fmt.Printf("  Token: %s\n", config.MaskToken(token))
+		}
+		if baseURL := app.ExtractBaseURL(provider); baseURL != "" {
+			fmt.Printf("  URL: %s\n", baseURL)
+		}
+
+		paths, err := app.LivePaths(manager)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n配置已写入:\n")
+		for _, path := range paths {
+			fmt.Printf("  %s\n", path)
+		}
+		return nil
+	},
+}
+
+func init() {
+	appCmd.AddCommand(appListCmd)
+	appCmd.AddCommand(appSwitchCmd)
+	rootCmd.AddCommand(appCmd)
+}
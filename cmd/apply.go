@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var projectApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "切换到当前项目 .ccs.toml 固定的配置",
+	Long: `从当前目录逐级向上查找 .ccs.toml，并将其中固定的所有应用切换到对应配置。
+已经是固定配置的应用会被跳过。
+
+示例:
+  ccs apply`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := project.Discover()
+		if err != nil {
+			return err
+		}
+		if f == nil || f.IsEmpty() {
+			return fmt.Errorf("未找到包含固定配置的 %s", project.FileName)
+		}
+
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		fmt.Printf("项目配置: %s\n", f.Path)
+		var failed int
+		for _, appName := range project.Apps {
+			pinned := f.Get(appName)
+			if pinned == "" {
+				continue
+			}
+			if current := manager.GetCurrentProviderForApp(appName); current != nil && current.Name == pinned {
+				fmt.Printf("  %s: 已是 %s\n", appName, pinned)
+				continue
+			}
+			if err := manager.SwitchProviderForApp(appName, pinned); err != nil {
+				fmt.Printf("✗ %s: 切换到 %s 失败: %v\n", appName, pinned, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ %s: 已切换到 %s\n", appName, pinned)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d 个应用切换失败", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(projectApplyCmd)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var endpointApp string
+
+var endpointCmd = &cobra.Command{
+	Use:     "endpoint",
+	Aliases: []string{"ep"},
+	Short:   "管理供应商的自定义端点",
+	Long: `管理供应商的自定义端点（与 GUI v3.5.0+ 的 meta.custom_endpoints 兼容）。
+
+示例:
+  ccs endpoint list myrelay
+  ccs endpoint add myrelay https://api-hk.example.com
+  ccs endpoint use myrelay https://api-hk.example.com
+  ccs endpoint remove myrelay https://api-hk.example.com
+  ccs ep list mycodex --app codex`,
+}
+
+var endpointListCmd = &cobra.Command{
+	Use:   "list <配置名称>",
+	Short: "列出供应商的所有端点",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		provider, err := manager.GetProviderForApp(endpointApp, args[0])
+		if err != nil {
+			return err
+		}
+
+		baseURL := config.ExtractAppBaseURLFromProvider(endpointApp, provider)
+		fmt.Printf("%s 的端点:\n", provider.Name)
+		fmt.Println("─────────────────────────────")
+		fmt.Printf("● %s (当前)\n", baseURL)
+
+		if provider.Meta == nil || len(provider.Meta.CustomEndpoints) == 0 {
+			fmt.Println("\n暂无自定义端点，使用 'ccs endpoint add <配置名称> <url>' 添加")
+			return nil
+		}
+
+		urls := make([]string, 0, len(provider.Meta.CustomEndpoints))
+		for url := range provider.Meta.CustomEndpoints {
+			urls = append(urls, url)
+		}
+		sort.Strings(urls)
+
+		current := strings.TrimRight(baseURL, "/")
+		for _, url := range urls {
+			if url == current {
+				continue
+			}
+			entry := provider.Meta.CustomEndpoints[url]
+			line := fmt.Sprintf("○ %s", url)
+			if entry.LastUsed != nil {
+				line += fmt.Sprintf("  (最后使用: %s)", time.UnixMilli(*entry.LastUsed).Format("2006-01-02 15:04:05"))
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+var endpointAddCmd = &cobra.Command{
+	Use:   "add <配置名称> <url>",
+	Short: "添加自定义端点",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+		if err := manager.AddCustomEndpoint(endpointApp, args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("✓ 已为 %s 添加端点: %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+var endpointRemoveCmd = &cobra.Command{
+	Use:     "remove <配置名称> <url>",
+	Aliases: []string{"rm", "delete"},
+	Short:   "删除自定义端点",
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+		if err := manager.RemoveCustomEndpoint(endpointApp, args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("✓ 已删除 %s 的端点: %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+var endpointUseCmd = &cobra.Command{
+	Use:   "use <配置名称> <url>",
+	Short: "将供应商的 Base URL 切换到指定端点",
+	Long: `将供应商的 Base URL 切换到指定端点，并记录最后使用时间。
+
+原 Base URL 会保留在自定义端点列表中。如果该供应商是当前激活配置，会同时更新 live 配置文件。`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+		if err := manager.UseEndpoint(endpointApp, args[0], args[1]); err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ %s 已切换到端点: %s\n", args[0], args[1])
+		if current := manager.GetCurrentProviderForApp(endpointApp); current != nil && current.Name == args[0] {
+			fmt.Println("  已同步更新 live 配置")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(endpointCmd)
+
+	endpointCmd.PersistentFlags().StringVar(&endpointApp, "app", "claude", "应用名称 (claude, codex 或 gemini)")
+
+	endpointCmd.AddCommand(endpointListCmd)
+	endpointCmd.AddCommand(endpointAddCmd)
+	endpointCmd.AddCommand(endpointRemoveCmd)
+	endpointCmd.AddCommand(endpointUseCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/shell"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env [配置名称]",
+	Short: "输出配置对应的环境变量（仅在当前终端生效）",
+	Long: `输出让指定配置在当前终端生效的环境变量设置语句，不修改任何配置文件。
+未指定配置名称时使用当前激活的配置。
+
+配合 eval 使用：
+  eval "$(ccs env work)"                      # bash / zsh
+  ccs env work --shell fish | source          # fish
+  ccs env work --shell powershell | iex       # PowerShell
+
+也可以先执行 ccs shell-init 定义 ccs-use 函数，之后直接使用 ccs-use work。
+
+示例:
+  ccs env work
+  ccs env --app codex work
+  ccs env --unset`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appName, _ := cmd.Flags().GetString("app")
+		shellName, _ := cmd.Flags().GetString("shell")
+		unset, _ := cmd.Flags().GetBool("unset")
+
+		sh, err := shell.Normalize(shellName)
+		if err != nil {
+			return err
+		}
+
+		if unset {
+			names, err := config.ShellEnvNames(appName)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				fmt.Println(shell.Unset(sh, name))
+			}
+			return nil
+		}
+
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		} else {
+			current := manager.GetCurrentProviderForApp(appName)
+			if current == nil {
+				return fmt.Errorf("未指定配置名称，且 %s 没有激活的配置", appName)
+			}
+			name = current.Name
+		}
+
+		vars, err := manager.ProviderShellEnv(appName, name)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range vars {
+			fmt.Println(shell.Export(sh, v.Name, v.Value))
+		}
+
+		// 直接在终端中运行时提示用法（输出到 stderr，不影响 eval）
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			fmt.Fprintf(os.Stderr, "# 以上语句不会自动生效，请使用 eval \"$(ccs env %s)\" 或 ccs-use %s\n", name, name)
+		}
+		return nil
+	},
+}
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init",
+	Short: "输出定义 ccs-use 函数的 shell 初始化脚本",
+	Long: `输出 shell 初始化脚本，定义 ccs-use 函数，用于在当前终端切换配置。
+
+将以下内容加入 shell 配置文件：
+  eval "$(ccs shell-init)"                          # ~/.bashrc 或 ~/.zshrc
+  ccs shell-init --shell fish | source              # ~/.config/fish/config.fish
+  ccs shell-init --shell powershell | Out-String | iex   # $PROFILE
+
+之后即可使用：
+  ccs-use work             # 当前终端使用 work 配置
+  ccs-use --app codex dev  # Codex 配置
+  ccs-use --unset          # 清除相关环境变量`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shellName, _ := cmd.Flags().GetString("shell")
+		sh, err := shell.Normalize(shellName)
+		if err != nil {
+			return err
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			executable = "ccs"
+		}
+		fmt.Print(shell.InitScript(sh, executable))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(shellInitCmd)
+
+	envCmd.Flags().String("app", "claude", "应用名称 (claude, codex 或 gemini)")
+	envCmd.Flags().String("shell", "", "shell 类型 (bash, zsh, fish, powershell)，默认自动检测")
+	envCmd.Flags().Bool("unset", false, "输出清除环境变量的语句")
+
+	shellInitCmd.Flags().String("shell", "", "shell 类型 (bash, zsh, fish, powershell)，默认自动检测")
+}
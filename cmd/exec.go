@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/procexec"
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <配置名称> -- <命令> [参数...]",
+	Short: "使用指定配置运行命令（不切换全局配置）",
+	Long: `以指定配置的环境变量启动子进程，不修改当前激活的配置和 live 配置文件。
+适合 CI 和脚本中按次指定配置，避免多个进程同时改写 ~/.claude/settings.json。
+
+注入的环境变量：
+  Claude: 配置中的完整 env（ANTHROPIC_AUTH_TOKEN、ANTHROPIC_BASE_URL、模型等）
+  Codex:  OPENAI_API_KEY、OPENAI_BASE_URL
+  Gemini: .env 中的全部变量（GEMINI_API_KEY、GOOGLE_GEMINI_BASE_URL 等）
+
+子进程的退出码原样返回，收到的中断/终止信号会转发给子进程。
+
+示例:
+  ccs exec work -- claude -p "hello"
+  ccs exec --app codex dev -- codex exec "fix tests"
+  ccs exec --app gemini gm -- gemini`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appName, _ := cmd.Flags().GetString("app")
+		name, command := args[0], args[1:]
+		// 关闭 interspersed 后 "--" 会原样保留在参数中
+		if command[0] == "--" {
+			command = command[1:]
+		}
+		if len(command) == 0 {
+			return fmt.Errorf("缺少要执行的命令")
+		}
+
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		overrides, err := manager.ProviderExecEnv(appName, name)
+		if err != nil {
+			return err
+		}
+
+		code, err := procexec.Run(command[0], command[1:], procexec.MergeEnv(os.Environ(), overrides))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(code)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+
+	// 配置名称之后的参数全部交给子命令，不再解析为 ccs 的 flag
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().String("app", "claude", "应用名称 (claude, codex 或 gemini)")
+}
@@ -111,6 +111,7 @@ func filterConfig(fullConfig *config.MultiAppConfig, appName, providerName strin
 	filtered := &config.MultiAppConfig{
 		Version: fullConfig.Version,
 		Apps:    make(map[string]config.ProviderManager),
+		Secrets: fullConfig.Secrets,
 	}
 
 	// 过滤应用
@@ -198,6 +199,7 @@ func cleanConfig(fullConfig *config.MultiAppConfig) *config.MultiAppConfig {
 	cleaned := &config.MultiAppConfig{
 		Version: fullConfig.Version,
 		Apps:    make(map[string]config.ProviderManager),
+		Secrets: fullConfig.Secrets,
 	}
 
 	for appName, appConfig := range fullConfig.Apps {
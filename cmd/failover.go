@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/failover"
+	"github.com/spf13/cobra"
+)
+
+var failoverCmd = &cobra.Command{
+	Use:   "failover",
+	Short: "检查当前配置健康状态并自动切换到可用配置",
+	Long: `检查当前供应商的 Base URL 是否可用，不可用时按排序顺序切换到下一个健康的供应商。
+
+健康判定：能够建立连接且返回非 5xx 响应（401/404 等视为服务在线）。
+每次切换都会记录到配置目录下的 failover.log。
+
+示例:
+  ccs failover                          # 检查一次 Claude 配置
+  ccs failover --app codex --dry-run    # 只显示将要进行的切换
+  ccs failover --watch --interval 30s   # 持续监控，每 30 秒检查一次`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appName, _ := cmd.Flags().GetString("app")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		opts := failover.Options{
+			App:     appName,
+			Timeout: timeout,
+			DryRun:  dryRun,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if !watch {
+			return runFailoverOnce(ctx, opts)
+		}
+
+		if interval <= 0 {
+			return fmt.Errorf("检查间隔必须大于 0")
+		}
+
+		fmt.Printf("开始监控 %s 配置，每 %s 检查一次 (Ctrl+C 退出)\n", appName, interval)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := runFailoverOnce(ctx, opts); err != nil {
+				fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			}
+			select {
+			case <-ctx.Done():
+				fmt.Println("\n已停止监控")
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// runFailoverOnce 执行一轮检查；每轮都重新加载配置，避免覆盖其他进程的修改
+func runFailoverOnce(ctx context.Context, opts failover.Options) error {
+	manager, err := getManager()
+	if err != nil {
+		return fmt.Errorf("初始化配置管理器失败: %w", err)
+	}
+
+	event, err := failover.RunOnce(ctx, manager, opts)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("15:04:05")
+	if event == nil {
+		current := manager.GetCurrentProviderForApp(opts.App)
+		fmt.Printf("[%s] ✓ %s 运行正常\n", now, current.Name)
+		return nil
+	}
+
+	if event.DryRun {
+		fmt.Printf("[%s] (dry-run) 将从 %s 切换到 %s\n", now, event.From, event.To)
+	} else {
+		fmt.Printf("[%s] ✓ 已从 %s 切换到 %s\n", now, event.From, event.To)
+	}
+	fmt.Printf("  原因: %s\n", event.Reason)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(failoverCmd)
+
+	failoverCmd.Flags().String("app", "claude", "应用名称 (claude, codex 或 gemini)")
+	failoverCmd.Flags().Duration("timeout", failover.DefaultTimeout, "健康检查超时时间")
+	failoverCmd.Flags().Bool("dry-run", false, "只显示将要进行的切换，不实际切换")
+	failoverCmd.Flags().Bool("watch", false, "持续监控模式")
+	failoverCmd.Flags().Duration("interval", failover.DefaultInterval, "监控模式的检查间隔")
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var historyForce bool
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "列出操作历史",
+	Long: `列出最近的修改操作（添加、更新、删除、切换、MCP 修改、模板应用）。
+
+每条记录保存了 config.json 和受影响 live 文件修改前后的内容，
+可通过 'ccs undo' / 'ccs redo' 撤销或重做。最多保留 ` + strconv.Itoa(history.MaxEntries) + ` 条。
+
+示例:
+  ccs history
+  ccs undo
+  ccs undo 3
+  ccs redo`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		entries, err := manager.History()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("暂无操作历史")
+			return nil
+		}
+
+		fmt.Println("操作历史（最新在前）:")
+		fmt.Println("─────────────────────────────")
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			mark := "●"
+			if e.Undone {
+				mark = "○"
+			}
+			line := fmt.Sprintf("%s #%-4d %s  %s (%d 个文件)", mark, e.Seq, e.Time.Format("2006-01-02 15:04:05"), e.Summary, len(e.Paths))
+			if e.Undone {
+				line += "  [已撤销]"
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "撤销最近 n 次操作（默认 1）",
+	Long: `撤销最近 n 次操作，将 config.json 和 live 文件恢复到操作前的内容。
+
+文件在操作之后被其他程序修改时默认拒绝撤销，使用 --force 覆盖这些修改。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := parseHistoryCount(args)
+		if err != nil {
+			return err
+		}
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		entries, err := manager.Undo(n, historyForce)
+		if err != nil {
+			return historyError(err)
+		}
+		for _, e := range entries {
+			fmt.Printf("✓ 已撤销 #%d: %s\n", e.Seq, e.Summary)
+		}
+		return nil
+	},
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo [n]",
+	Short: "重做最近撤销的 n 次操作（默认 1）",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := parseHistoryCount(args)
+		if err != nil {
+			return err
+		}
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		entries, err := manager.Redo(n, historyForce)
+		if err != nil {
+			return historyError(err)
+		}
+		for _, e := range entries {
+			fmt.Printf("✓ 已重做 #%d: %s\n", e.Seq, e.Summary)
+		}
+		return nil
+	},
+}
+
+// parseHistoryCount 解析撤销/重做的次数参数
+func parseHistoryCount(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("无效的次数: %s", args[0])
+	}
+	return n, nil
+}
+
+// historyError 为文件已被修改的错误补充提示
+func historyError(err error) error {
+	if errors.Is(err, history.ErrDiverged) {
+		return fmt.Errorf("%w\n如确认要覆盖这些修改，请使用 --force", err)
+	}
+	return err
+}
+
+func init() {
+	undoCmd.Flags().BoolVar(&historyForce, "force", false, "文件已被修改时仍然覆盖")
+	redoCmd.Flags().BoolVar(&historyForce, "force", false, "文件已被修改时仍然覆盖")
+
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+}
@@ -45,6 +45,17 @@ func importFromFile(manager *config.Manager, filePath, appName string) error {
 		return fmt.Errorf("读取导入文件失败: %w", err)
 	}
 
+	// 导入文件中的 Token 已加密：先用该文件的口令解密，保存时再按本地设置处理
+	if importConfig.Secrets != nil {
+		passphrase, err := promptPassphrase("导入文件中的 Token 已加密，请输入该文件的口令: ")
+		if err != nil {
+			return fmt.Errorf("读取口令失败: %w", err)
+		}
+		if err := config.DecryptConfigSecrets(&importConfig, passphrase); err != nil {
+			return fmt.Errorf("解密导入文件失败: %w", err)
+		}
+	}
+
 	// 导入配置
 	importedCount := 0
 	skippedCount := 0
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/lock"
+	"github.com/spf13/cobra"
+)
+
+var lockBreakForce bool
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "查看或清除配置锁",
+	Long: `cc-switch 使用两种锁:
+
+  配置文件锁 (config.json.lock)  每次保存 config.json 时持有的 OS 咨询锁（flock），
+                                 进程退出后由系统自动释放
+  TUI 实例锁 (.cc-switch.lock)   记录正在运行的 TUI 进程 PID，防止同时打开多个界面
+
+示例:
+  ccs lock status
+  ccs lock break
+  ccs lock break --force`,
+}
+
+var lockStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "显示锁的持有者及其是否存活",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		configLockPath := manager.ConfigLockPath()
+		info, err := lock.Inspect(configLockPath)
+		if err != nil {
+			return fmt.Errorf("检查配置文件锁失败: %w", err)
+		}
+		fmt.Printf("配置文件锁: %s\n", configLockPath)
+		switch {
+		case info.Held:
+			fmt.Printf("  状态: 已被占用 (%s)\n", describeHolder(info.PID, info.Alive))
+		default:
+			fmt.Println("  状态: 空闲")
+		}
+
+		instanceLock := lock.NewLock(filepath.Dir(manager.GetConfigPath()))
+		fmt.Printf("TUI 实例锁: %s\n", instanceLock.Path())
+		pid, err := instanceLock.GetPID()
+		switch {
+		case os.IsNotExist(err):
+			fmt.Println("  状态: 空闲")
+		case err != nil:
+			fmt.Printf("  状态: 无法读取 (%v)\n", err)
+		default:
+			alive := lock.ProcessAlive(pid)
+			fmt.Printf("  状态: 已被占用 (%s)\n", describeHolder(pid, alive))
+			if !alive {
+				fmt.Println("  提示: 持有者已退出，可使用 'ccs lock break' 清除")
+			}
+		}
+		return nil
+	},
+}
+
+var lockBreakCmd = &cobra.Command{
+	Use:   "break",
+	Short: "清除已失效的锁",
+	Long: `清除持有者已退出的锁文件。
+
+TUI 实例锁的持有者仍在运行时需加 --force 才会清除。
+配置文件锁由系统管理，持有者正在写入时无法清除，等待其完成即可。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		configLockPath := manager.ConfigLockPath()
+		info, err := lock.Inspect(configLockPath)
+		if err != nil {
+			return fmt.Errorf("检查配置文件锁失败: %w", err)
+		}
+		if info.Held {
+			return fmt.Errorf("配置文件锁正被使用 (%s)，进程结束后会自动释放", describeHolder(info.PID, info.Alive))
+		}
+		if info.Exists {
+			if err := os.Remove(configLockPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("删除配置文件锁失败: %w", err)
+			}
+			fmt.Printf("✓ 已清除配置文件锁: %s\n", configLockPath)
+		}
+
+		instanceLock := lock.NewLock(filepath.Dir(manager.GetConfigPath()))
+		pid, err := instanceLock.GetPID()
+		if os.IsNotExist(err) {
+			fmt.Println("TUI 实例锁: 空闲")
+			return nil
+		}
+		if err == nil && lock.ProcessAlive(pid) && !lockBreakForce {
+			return fmt.Errorf("TUI 实例锁的持有者仍在运行 (PID %d)，如确认要清除请使用 --force", pid)
+		}
+		if err := os.Remove(instanceLock.Path()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除 TUI 实例锁失败: %w", err)
+		}
+		fmt.Printf("✓ 已清除 TUI 实例锁: %s\n", instanceLock.Path())
+		return nil
+	},
+}
+
+// describeHolder 格式化锁持有者 PID 及存活状态
+func describeHolder(pid int, alive bool) string {
+	if pid == 0 {
+		return "持有者未知"
+	}
+	if alive {
+		return fmt.Sprintf("PID %d，运行中", pid)
+	}
+	return fmt.Sprintf("PID %d，已退出", pid)
+}
+
+func init() {
+	lockBreakCmd.Flags().BoolVar(&lockBreakForce, "force", false, "持有者仍在运行时也清除 TUI 实例锁")
+
+	lockCmd.AddCommand(lockStatusCmd)
+	lockCmd.AddCommand(lockBreakCmd)
+	rootCmd.AddCommand(lockCmd)
+}
@@ -184,14 +184,13 @@ func migrateClaudeConfig(oldPath, newPath string) error {
 	}
 
 	// 合并配置（优先保留新配置的值）
-	if newConfig.Env.AnthropicAuthToken == "" && oldConfig.Env.AnthropicAuthToken != "" {
-		newConfig.Env.AnthropicAuthToken = oldConfig.Env.AnthropicAuthToken
+	if newConfig.Env == nil {
+		newConfig.Env = config.ClaudeEnv{}
 	}
-	if newConfig.Env.AnthropicBaseURL == "" && oldConfig.Env.AnthropicBaseURL != "" {
-		newConfig.Env.AnthropicBaseURL = oldConfig.Env.AnthropicBaseURL
-	}
-	if newConfig.Env.ClaudeCodeModel == "" && oldConfig.Env.ClaudeCodeModel != "" {
-		newConfig.Env.ClaudeCodeModel = oldConfig.Env.ClaudeCodeModel
+	for key, val := range oldConfig.Env {
+		if _, exists := newConfig.Env[key]; !exists {
+			newConfig.Env[key] = val
+		}
 	}
 
 	// 保存合并后的配置
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var projectApp string
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "管理项目固定的配置 (.ccs.toml)",
+	Long: `在项目目录中通过 .ccs.toml 声明各应用使用的配置，例如:
+
+  claude = "corp-relay"
+  codex = "dev"
+
+查找时从当前目录开始逐级向上，使用找到的第一个 .ccs.toml。
+使用 'ccs apply' 切换到项目固定的配置。
+
+示例:
+  ccs project pin corp-relay
+  ccs project pin dev --app codex
+  ccs project unpin --app codex
+  ccs project status`,
+}
+
+var projectPinCmd = &cobra.Command{
+	Use:   "pin <配置名称>",
+	Short: "将当前项目固定到指定配置",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+		if _, err := manager.GetProviderForApp(projectApp, args[0]); err != nil {
+			return err
+		}
+
+		f, err := project.Discover()
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			f = &project.File{Path: filepath.Join(cwd, project.FileName)}
+		}
+
+		if err := f.Set(projectApp, args[0]); err != nil {
+			return err
+		}
+		if err := f.Save(); err != nil {
+			return fmt.Errorf("保存项目配置失败: %w", err)
+		}
+
+		fmt.Printf("✓ 已将 %s 固定到配置: %s\n", projectApp, args[0])
+		fmt.Printf("  文件: %s\n", f.Path)
+		return nil
+	},
+}
+
+var projectUnpinCmd = &cobra.Command{
+	Use:   "unpin",
+	Short: "取消当前项目对指定应用的固定",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := project.Discover()
+		if err != nil {
+			return err
+		}
+		if f == nil || f.Get(projectApp) == "" {
+			fmt.Printf("%s 未固定配置\n", projectApp)
+			return nil
+		}
+
+		if err := f.Set(projectApp, ""); err != nil {
+			return err
+		}
+		// 没有任何固定时删除文件，避免留下空的 .ccs.toml
+		if f.IsEmpty() {
+			if err := os.Remove(f.Path); err != nil {
+				return fmt.Errorf("删除项目配置失败: %w", err)
+			}
+			fmt.Printf("✓ 已取消 %s 的固定，并删除 %s\n", projectApp, f.Path)
+			return nil
+		}
+		if err := f.Save(); err != nil {
+			return fmt.Errorf("保存项目配置失败: %w", err)
+		}
+		fmt.Printf("✓ 已取消 %s 的固定\n", projectApp)
+		return nil
+	},
+}
+
+var projectStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "显示项目固定的配置与当前配置",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := project.Discover()
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			fmt.Printf("未找到 %s，使用 'ccs project pin <配置名称>' 创建\n", project.FileName)
+			return nil
+		}
+
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		fmt.Printf("项目配置: %s\n", f.Path)
+		fmt.Println("─────────────────────────────")
+		for _, appName := range project.Apps {
+			pinned := f.Get(appName)
+			if pinned == "" {
+				continue
+			}
+			currentName := ""
+			if current := manager.GetCurrentProviderForApp(appName); current != nil {
+				currentName = current.Name
+			}
+			if currentName == pinned {
+				fmt.Printf("✓ %-7s %s\n", appName, pinned)
+			} else {
+				fmt.Printf("✗ %-7s %s (当前: %s)\n", appName, pinned, displayOrNone(currentName))
+			}
+		}
+		return nil
+	},
+}
+
+// displayOrNone 空字符串显示为"无"
+func displayOrNone(s string) string {
+	if s == "" {
+		return "无"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+
+	projectCmd.PersistentFlags().StringVar(&projectApp, "app", "claude", "应用名称 (claude, codex 或 gemini)")
+
+	projectCmd.AddCommand(projectPinCmd)
+	projectCmd.AddCommand(projectUnpinCmd)
+	projectCmd.AddCommand(projectStatusCmd)
+}
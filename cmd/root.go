@@ -19,6 +19,7 @@ import (
 
 var configDir string
 var noLock bool
+var switchScope string
 
 var rootCmd = &cobra.Command{
 	Use:   "cc-switch [配置名称]",
@@ -28,6 +29,7 @@ var rootCmd = &cobra.Command{
 使用方法：
   cc-switch              启动交互式 TUI 界面
   cc-switch <配置名称>    切换到指定配置
+  cc-switch <配置名称> --scope local  写入项目 .claude/settings.local.json
   cc-switch ui           明确启动 TUI 界面
   cc-switch config add   添加新配置
   cc-switch config delete 删除配置`,
@@ -57,7 +59,7 @@ var rootCmd = &cobra.Command{
 
 		// 单参数：切换配置
 		configName := args[0]
-		return switchConfig(manager, configName)
+		return switchConfig(manager, configName, switchScope)
 	},
 }
 
@@ -78,6 +80,7 @@ func init() {
 	// 添加全局 flag
 	rootCmd.PersistentFlags().StringVar(&configDir, "dir", "", "使用自定义配置目录")
 	rootCmd.PersistentFlags().BoolVar(&noLock, "no-lock", false, "禁用单实例锁（允许多个实例同时运行）")
+	rootCmd.Flags().StringVar(&switchScope, "scope", config.ClaudeScopeGlobal, "Claude 设置写入范围 (global, project 或 local)")
 
 	// 自定义帮助模板
 	rootCmd.SetHelpTemplate(`{{.Long}}
@@ -130,13 +133,18 @@ func listConfigs(manager *config.Manager) error {
 	return nil
 }
 
-func switchConfig(manager *config.Manager, name string) error {
+func switchConfig(manager *config.Manager, name, scope string) error {
 	provider, err := manager.GetProvider(name)
 	if err != nil {
 		return fmt.Errorf("配置不存在: %s", name)
 	}
 
-	if err := manager.SwitchProvider(name); err != nil {
+	settingsPath, err := manager.GetClaudeSettingsPathForScope(scope)
+	if err != nil {
+		return err
+	}
+
+	if err := manager.SwitchClaudeProviderToScope(name, scope); err != nil {
 		return fmt.Errorf("切换配置失败: %w", err)
 	}
 
@@ -146,6 +154,9 @@ func switchConfig(manager *config.Manager, name string) error {
 	fmt.Printf("✓ 已切换到配置: %s\n", name)
 	fmt.Printf("  Token: %s\n", config.MaskToken(token))
 	fmt.Printf("  URL: %s\n", baseURL)
+	if scope != "" && scope != config.ClaudeScopeGlobal {
+		fmt.Printf("  文件: %s\n", settingsPath)
+	}
 
 	return nil
 }
@@ -188,6 +199,11 @@ func startTUI(manager *config.Manager) error {
 		defer instanceLock.Release()
 	}
 
+	// 加密模式下需在进入全屏界面前解锁，TUI 中无法交互输入口令
+	if err := manager.UnlockSecrets(); err != nil {
+		return fmt.Errorf("解锁加密 Token 失败: %w", err)
+	}
+
 	model := tui.New(manager)
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
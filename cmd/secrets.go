@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	// passphraseEnv 非交互场景下提供加密口令的环境变量
+	passphraseEnv = "CCS_PASSPHRASE"
+	// newPassphraseEnv rotate 时提供新口令的环境变量
+	newPassphraseEnv = "CCS_NEW_PASSPHRASE"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "管理 API Token 加密",
+	Long: `管理 config.json 中 API Token 的加密存储。
+
+启用后，ANTHROPIC_AUTH_TOKEN、OPENAI_API_KEY、GEMINI_API_KEY 等字段会使用
+口令派生的密钥（scrypt + AES-256-GCM）加密保存，备份、导出文件中也只包含密文。
+Token 仅在写入 live 配置文件时在内存中解密。
+
+口令来源：
+  - 环境变量 CCS_PASSPHRASE（适用于脚本）
+  - 否则在终端中交互输入
+
+Token 也可以填写为外部密钥引用，切换时才解析，config.json 中只保存引用本身：
+  env:MY_RELAY_KEY           读取环境变量
+  file:/run/secrets/relay    读取文件内容
+  pass:relay/key             读取 pass 密码库（第一行）
+  cmd:<命令>                 执行命令并取标准输出
+
+示例:
+  ccs secrets status
+  ccs secrets enable
+  ccs secrets rotate
+  ccs secrets disable`,
+}
+
+var secretsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "查看加密状态",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		encrypted, plaintext, references := manager.SecretStats()
+		if manager.SecretsEnabled() {
+			fmt.Println("Token 加密: 已启用")
+		} else {
+			fmt.Println("Token 加密: 未启用")
+		}
+		fmt.Printf("已加密 Token: %d\n", encrypted)
+		fmt.Printf("明文 Token:   %d\n", plaintext)
+		fmt.Printf("外部引用:     %d\n", references)
+		return nil
+	},
+}
+
+var secretsEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "启用加密并加密所有 Token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+		if manager.SecretsEnabled() {
+			return fmt.Errorf("Token 加密已启用")
+		}
+
+		passphrase, err := readNewPassphrase(passphraseEnv)
+		if err != nil {
+			return err
+		}
+		if err := manager.EnableSecrets(passphrase); err != nil {
+			return fmt.Errorf("启用加密失败: %w", err)
+		}
+
+		encrypted, _, _ := manager.SecretStats()
+		fmt.Printf("✓ 已启用 Token 加密，共加密 %d 个 Token\n", encrypted)
+		fmt.Println("  请牢记口令，丢失后无法恢复已加密的 Token")
+		return nil
+	},
+}
+
+var secretsDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "禁用加密并以明文保存所有 Token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+		if err := manager.DisableSecrets(); err != nil {
+			return fmt.Errorf("禁用加密失败: %w", err)
+		}
+		fmt.Println("✓ 已禁用 Token 加密，所有 Token 已以明文保存")
+		return nil
+	},
+}
+
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "更换口令并重新加密所有 Token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+		if !manager.SecretsEnabled() {
+			return fmt.Errorf("Token 加密未启用")
+		}
+
+		// 先用旧口令解锁
+		if err := manager.UnlockSecrets(); err != nil {
+			return err
+		}
+
+		fmt.Println("请设置新口令")
+		passphrase, err := readNewPassphrase(newPassphraseEnv)
+		if err != nil {
+			return err
+		}
+		if err := manager.RotateSecrets(passphrase); err != nil {
+			return fmt.Errorf("更换口令失败: %w", err)
+		}
+		fmt.Println("✓ 已使用新口令重新加密所有 Token")
+		return nil
+	},
+}
+
+// promptPassphrase 读取加密口令：优先使用环境变量，否则在终端交互输入
+func promptPassphrase(prompt string) (string, error) {
+	if value := os.Getenv(passphraseEnv); value != "" {
+		return value, nil
+	}
+	return readPassword(prompt)
+}
+
+// readPassword 从终端读取口令（不回显）
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("无法交互输入口令，请设置环境变量 %s", passphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readNewPassphrase 读取新口令：优先使用环境变量，否则交互输入两次确认
+func readNewPassphrase(envName string) (string, error) {
+	if value := os.Getenv(envName); value != "" {
+		return value, nil
+	}
+
+	first, err := readPassword("请输入新口令: ")
+	if err != nil {
+		return "", err
+	}
+	if first == "" {
+		return "", fmt.Errorf("口令不能为空")
+	}
+	second, err := readPassword("请再次输入新口令: ")
+	if err != nil {
+		return "", err
+	}
+	if first != second {
+		return "", fmt.Errorf("两次输入的口令不一致")
+	}
+	return first, nil
+}
+
+func init() {
+	config.SetDefaultPassphraseFunc(promptPassphrase)
+
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsStatusCmd)
+	secretsCmd.AddCommand(secretsEnableCmd)
+	secretsCmd.AddCommand(secretsDisableCmd)
+	secretsCmd.AddCommand(secretsRotateCmd)
+}
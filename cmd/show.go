@@ -51,6 +51,9 @@ var showCmd = &cobra.Command{
 				"createdAt":      provider.CreatedAt,
 				"isCurrent":      isCurrent,
 			}
+			if provider.Meta != nil {
+				output["meta"] = provider.Meta
+			}
 			data, err := json.MarshalIndent(output, "", "  ")
 			if err != nil {
 				return fmt.Errorf("序列化失败: %w", err)
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/speedtest"
+	"github.com/spf13/cobra"
+)
+
+var speedtestCmd = &cobra.Command{
+	Use:     "speedtest [配置名称]",
+	Aliases: []string{"st"},
+	Short:   "测试供应商端点延迟",
+	Long: `并发测试供应商 Base URL 及所有自定义端点的延迟，并按平均延迟排序输出。
+
+任何 HTTP 响应（包括 401/404）都视为端点可达；连接失败和超时计为失败。
+
+示例:
+  ccs speedtest                        # 测试所有 Claude 配置
+  ccs speedtest myrelay                # 只测试指定配置
+  ccs speedtest --app codex -n 5       # 测试 Codex 配置，每个端点 5 次
+  ccs speedtest --timeout 3s --json    # JSON 格式输出`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appName, _ := cmd.Flags().GetString("app")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		count, _ := cmd.Flags().GetInt("count")
+		jsonFormat, _ := cmd.Flags().GetBool("json")
+
+		manager, err := getManager()
+		if err != nil {
+			return fmt.Errorf("初始化配置管理器失败: %w", err)
+		}
+
+		var providers []config.Provider
+		if len(args) == 1 {
+			provider, err := manager.GetProviderForApp(appName, args[0])
+			if err != nil {
+				return err
+			}
+			providers = []config.Provider{*provider}
+		} else {
+			providers = manager.ListProvidersForApp(appName)
+		}
+
+		targets := speedtest.CollectTargets(appName, providers)
+		if len(targets) == 0 {
+			return fmt.Errorf("没有可测试的端点")
+		}
+
+		if !jsonFormat {
+			fmt.Printf("正在测试 %d 个端点 (每个 %d 次, 超时 %s)...\n\n", len(targets), count, timeout)
+		}
+
+		results := speedtest.Run(context.Background(), targets, speedtest.Options{
+			Timeout: timeout,
+			Count:   count,
+		})
+
+		if jsonFormat {
+			return printSpeedtestJSON(results)
+		}
+		printSpeedtestTable(results)
+		return nil
+	},
+}
+
+// formatLatency 以毫秒显示延迟
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+func printSpeedtestTable(results []speedtest.Result) {
+	fmt.Printf("%-4s %-20s %-40s %8s %8s %8s %6s\n", "#", "配置", "端点", "最小", "平均", "P95", "失败")
+	fmt.Println("──────────────────────────────────────────────────────────────────────────────────────────────")
+
+	for i, r := range results {
+		url := r.URL
+		if r.Custom {
+			url += " *"
+		}
+		if !r.OK() {
+			fmt.Printf("%-4d %-20s %-40s %8s %8s %8s %3d/%-2d\n", i+1, r.Provider, url, "-", "-", "-", r.Failures, r.Attempts)
+			continue
+		}
+		fmt.Printf("%-4d %-20s %-40s %8s %8s %8s %3d/%-2d\n",
+			i+1, r.Provider, url,
+			formatLatency(r.Min), formatLatency(r.Avg), formatLatency(r.P95),
+			r.Failures, r.Attempts)
+	}
+
+	fmt.Println("\n* 表示自定义端点")
+	for _, r := range results {
+		if r.LastError != "" {
+			fmt.Printf("✗ %s (%s): %s\n", r.Provider, r.URL, r.LastError)
+		}
+	}
+}
+
+func printSpeedtestJSON(results []speedtest.Result) error {
+	output := make([]map[string]interface{}, 0, len(results))
+	for _, r := range results {
+		item := map[string]interface{}{
+			"provider": r.Provider,
+			"url":      r.URL,
+			"custom":   r.Custom,
+			"ok":       r.OK(),
+			"attempts": r.Attempts,
+			"failures": r.Failures,
+		}
+		if r.OK() {
+			item["min_ms"] = r.Min.Milliseconds()
+			item["avg_ms"] = r.Avg.Milliseconds()
+			item["p95_ms"] = r.P95.Milliseconds()
+			item["status"] = r.Status
+		}
+		if r.LastError != "" {
+			item["error"] = r.LastError
+		}
+		output = append(output, item)
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化失败: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(speedtestCmd)
+
+	speedtestCmd.Flags().String("app", "claude", "应用名称 (claude, codex 或 gemini)")
+	speedtestCmd.Flags().Duration("timeout", speedtest.DefaultTimeout, "单次请求超时时间")
+	speedtestCmd.Flags().IntP("count", "n", speedtest.DefaultCount, "每个端点的测试次数")
+	speedtestCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+}
@@ -101,8 +101,14 @@ func runApplyTemplate(templateID string) {
 		}
 	}
 
-	// 应用模板
-	if err := tm.ApplyTemplate(templateID, targetPath); err != nil {
+	// 应用模板（记录到操作历史，便于 ccs undo 撤销）
+	finish := func() {}
+	if manager, err := getManager(); err == nil {
+		finish = manager.BeginOperation(fmt.Sprintf("应用模板 '%s' 到 %s", tpl.Name, targetPath), targetPath)
+	}
+	err = tm.ApplyTemplate(templateID, targetPath)
+	finish()
+	if err != nil {
 		color.Red("Error: Failed to apply template: %v", err)
 		os.Exit(1)
 	}
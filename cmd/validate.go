@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/secrets"
 	"github.com/spf13/cobra"
 )
 
@@ -261,8 +262,8 @@ func validateClaudeProvider(appName string, provider config.Provider, issues, wa
 			Message:  "缺少 ANTHROPIC_AUTH_TOKEN",
 		})
 	} else {
-		// 验证 Token 格式
-		if !strings.HasPrefix(token, "sk-") && !strings.HasPrefix(token, "88_") {
+		// 验证 Token 格式（已加密的 Token 和外部引用无法校验格式）
+		if !secrets.IsEncrypted(token) && !secrets.IsReference(token) && !strings.HasPrefix(token, "sk-") && !strings.HasPrefix(token, "88_") {
 			*warnings = append(*warnings, ValidationIssue{
 				Level:    "WARNING",
 				App:      appName,
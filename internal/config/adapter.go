@@ -0,0 +1,281 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/writeset"
+)
+
+// FormField 新增/编辑供应商时的表单字段
+type FormField struct {
+	Key    string `json:"key"`              // 字段标识（自定义应用中即 settingsConfig.env 的键）
+	Label  string `json:"label"`            // 显示名称
+	Kind   string `json:"kind,omitempty"`   // 字段含义：token、baseUrl、model，其他字段为空
+	Secret bool   `json:"secret,omitempty"` // 是否为敏感信息（表单中默认隐藏）
+}
+
+// 表单字段含义
+const (
+	FieldKindToken   = "token"
+	FieldKindBaseURL = "baseUrl"
+	FieldKindModel   = "model"
+)
+
+// AppAdapter 描述一个受支持的 CLI 应用：live 文件、写入与回填方式、MCP 同步和表单字段
+// 新增应用只需实现该接口并注册，切换、回填和 MCP 同步流程无需修改
+type AppAdapter interface {
+	// Name 应用标识，即 config.json 中的顶层键（如 claude）
+	Name() string
+	// DisplayName 界面中显示的名称（如 Claude Code）
+	DisplayName() string
+	// LivePaths 返回应用读取的 live 配置文件路径
+	LivePaths(m *Manager) ([]string, error)
+	// Stage 将供应商配置写入 live 文件（加入写入集合，随 config.json 一起提交）
+	Stage(m *Manager, ws *writeset.WriteSet, provider *Provider) error
+	// Backfill 将 live 文件中的修改合并回供应商，返回供应商是否发生变化
+	Backfill(m *Manager, provider *Provider) (bool, error)
+	// ExtractToken 提取供应商的 API Key
+	ExtractToken(p *Provider) string
+	// ExtractBaseURL 提取供应商的 Base URL
+	ExtractBaseURL(p *Provider) string
+	// SupportsMcp 是否支持 MCP 服务器同步
+	SupportsMcp() bool
+	// SyncMcp 将单个 MCP 服务器写入应用配置，server 为 nil 时移除
+	SyncMcp(m *Manager, id string, server map[string]interface{}) error
+	// SyncAllMcp 用给定的服务器集合整体替换应用中的 MCP 配置
+	SyncAllMcp(m *Manager, servers map[string]interface{}) error
+	// FormFields 返回新增/编辑供应商时的表单字段
+	FormFields() []FormField
+}
+
+// registeredApps 内置应用，按注册顺序排列（决定界面中的切换顺序）
+var registeredApps []AppAdapter
+
+// RegisterApp 注册内置应用，同名应用会被替换
+func RegisterApp(a AppAdapter) {
+	for i, existing := range registeredApps {
+		if existing.Name() == a.Name() {
+			registeredApps[i] = a
+			return
+		}
+	}
+	registeredApps = append(registeredApps, a)
+}
+
+func init() {
+	RegisterApp(claudeAdapter{})
+	RegisterApp(codexAdapter{})
+	RegisterApp(geminiAdapter{})
+}
+
+// IsBuiltinApp 是否为内置应用
+func IsBuiltinApp(name string) bool {
+	for _, a := range registeredApps {
+		if a.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Apps 返回所有可用应用：内置应用在前，自定义应用按名称排序
+func (m *Manager) Apps() []AppAdapter {
+	apps := make([]AppAdapter, 0, len(registeredApps)+len(m.customApps))
+	apps = append(apps, registeredApps...)
+	apps = append(apps, m.customApps...)
+	return apps
+}
+
+// AppNames 返回所有可用应用的标识
+func (m *Manager) AppNames() []string {
+	apps := m.Apps()
+	names := make([]string, len(apps))
+	for i, a := range apps {
+		names[i] = a.Name()
+	}
+	return names
+}
+
+// App 返回指定应用的适配器
+func (m *Manager) App(name string) (AppAdapter, error) {
+	for _, a := range m.Apps() {
+		if a.Name() == name {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("不支持的应用: %s", name)
+}
+
+// AppDisplayName 返回应用的显示名称，未知应用返回标识本身
+func (m *Manager) AppDisplayName(name string) string {
+	if a, err := m.App(name); err == nil {
+		return a.DisplayName()
+	}
+	return name
+}
+
+// McpAppNames 返回支持 MCP 同步的应用标识
+func (m *Manager) McpAppNames() []string {
+	var names []string
+	for _, a := range m.Apps() {
+		if a.SupportsMcp() {
+			names = append(names, a.Name())
+		}
+	}
+	return names
+}
+
+// claudeAdapter Claude Code：~/.claude/settings.json
+type claudeAdapter struct{}
+
+func (claudeAdapter) Name() string        { return "claude" }
+func (claudeAdapter) DisplayName() string { return "Claude Code" }
+
+func (claudeAdapter) LivePaths(m *Manager) ([]string, error) {
+	settingsPath, err := m.GetClaudeSettingsPathWithDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{settingsPath}, nil
+}
+
+func (claudeAdapter) Stage(m *Manager, ws *writeset.WriteSet, provider *Provider) error {
+	return m.stageClaudeConfig(ws, provider)
+}
+
+func (claudeAdapter) Backfill(m *Manager, provider *Provider) (bool, error) {
+	return m.backfillClaudeConfig(provider)
+}
+
+func (claudeAdapter) ExtractToken(p *Provider) string   { return ExtractTokenFromProvider(p) }
+func (claudeAdapter) ExtractBaseURL(p *Provider) string { return ExtractBaseURLFromProvider(p) }
+func (claudeAdapter) SupportsMcp() bool                 { return true }
+
+func (claudeAdapter) SyncMcp(m *Manager, id string, server map[string]interface{}) error {
+	if server == nil {
+		return m.RemoveMcpFromClaude(id)
+	}
+	return m.SyncMcpToClaud(id)
+}
+
+func (claudeAdapter) SyncAllMcp(m *Manager, servers map[string]interface{}) error {
+	return m.syncMcpToClaudeBatch(servers)
+}
+
+func (claudeAdapter) FormFields() []FormField {
+	return []FormField{
+		{Key: "ANTHROPIC_AUTH_TOKEN", Label: "API Token", Kind: FieldKindToken, Secret: true},
+		{Key: "ANTHROPIC_BASE_URL", Label: "Base URL", Kind: FieldKindBaseURL},
+		{Key: "ANTHROPIC_MODEL", Label: "主模型", Kind: FieldKindModel},
+		{Key: "ANTHROPIC_DEFAULT_HAIKU_MODEL", Label: "Haiku 默认模型"},
+		{Key: "ANTHROPIC_DEFAULT_SONNET_MODEL", Label: "Sonnet 默认模型"},
+		{Key: "ANTHROPIC_DEFAULT_OPUS_MODEL", Label: "Opus 默认模型"},
+	}
+}
+
+// codexAdapter Codex CLI：~/.codex/auth.json 和 ~/.codex/config.toml
+type codexAdapter struct{}
+
+func (codexAdapter) Name() string        { return "codex" }
+func (codexAdapter) DisplayName() string { return "Codex CLI" }
+
+func (codexAdapter) LivePaths(m *Manager) ([]string, error) {
+	authPath, err := m.GetCodexAuthJsonPathWithDir()
+	if err != nil {
+		return nil, err
+	}
+	configPath, err := m.GetCodexConfigPathWithDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{authPath, configPath}, nil
+}
+
+func (codexAdapter) Stage(m *Manager, ws *writeset.WriteSet, provider *Provider) error {
+	return m.stageCodexConfig(ws, provider)
+}
+
+func (codexAdapter) Backfill(m *Manager, provider *Provider) (bool, error) {
+	return m.backfillCodexConfig(provider)
+}
+
+func (codexAdapter) ExtractToken(p *Provider) string   { return ExtractTokenFromProvider(p) }
+func (codexAdapter) ExtractBaseURL(p *Provider) string { return ExtractBaseURLFromProvider(p) }
+func (codexAdapter) SupportsMcp() bool                 { return true }
+
+func (codexAdapter) SyncMcp(m *Manager, id string, server map[string]interface{}) error {
+	if server == nil {
+		return m.RemoveMcpFromCodex(id)
+	}
+	return m.SyncMcpToCodex(id)
+}
+
+func (codexAdapter) SyncAllMcp(m *Manager, servers map[string]interface{}) error {
+	return m.syncMcpToCodexBatch(servers)
+}
+
+func (codexAdapter) FormFields() []FormField {
+	return []FormField{
+		{Key: "OPENAI_API_KEY", Label: "API Key", Kind: FieldKindToken, Secret: true},
+		{Key: "base_url", Label: "Base URL", Kind: FieldKindBaseURL},
+		{Key: "model", Label: "模型", Kind: FieldKindModel},
+		{Key: "model_reasoning_effort", Label: "推理强度"},
+	}
+}
+
+// geminiAdapter Gemini CLI：~/.gemini/.env 和 ~/.gemini/settings.json
+type geminiAdapter struct{}
+
+func (geminiAdapter) Name() string        { return "gemini" }
+func (geminiAdapter) DisplayName() string { return "Gemini CLI" }
+
+func (geminiAdapter) LivePaths(m *Manager) ([]string, error) {
+	envPath, err := m.GetGeminiEnvPathWithDir()
+	if err != nil {
+		return nil, err
+	}
+	settingsPath, err := m.GetGeminiSettingsPathWithDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{envPath, settingsPath}, nil
+}
+
+func (geminiAdapter) Stage(m *Manager, ws *writeset.WriteSet, provider *Provider) error {
+	return m.stageGeminiConfig(ws, provider)
+}
+
+func (geminiAdapter) Backfill(m *Manager, provider *Provider) (bool, error) {
+	return m.backfillGeminiConfig(provider)
+}
+
+func (geminiAdapter) ExtractToken(p *Provider) string {
+	_, apiKey, _, _ := ExtractGeminiConfigFromProvider(p)
+	return apiKey
+}
+
+func (geminiAdapter) ExtractBaseURL(p *Provider) string {
+	baseURL, _, _, _ := ExtractGeminiConfigFromProvider(p)
+	return baseURL
+}
+
+func (geminiAdapter) SupportsMcp() bool { return true }
+
+func (geminiAdapter) SyncMcp(m *Manager, id string, server map[string]interface{}) error {
+	if server == nil {
+		return m.RemoveMcpFromGemini(id)
+	}
+	return m.SyncMcpToGemini(id)
+}
+
+func (geminiAdapter) SyncAllMcp(m *Manager, servers map[string]interface{}) error {
+	return m.syncMcpToGeminiBatch(servers)
+}
+
+func (geminiAdapter) FormFields() []FormField {
+	return []FormField{
+		{Key: "GEMINI_API_KEY", Label: "API Key", Kind: FieldKindToken, Secret: true},
+		{Key: "GOOGLE_GEMINI_BASE_URL", Label: "Base URL", Kind: FieldKindBaseURL},
+		{Key: "GEMINI_MODEL", Label: "模型", Kind: FieldKindModel},
+	}
+}
@@ -0,0 +1,722 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	toml "github.com/pelletier/go-toml/v2"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/tomledit"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/writeset"
+)
+
+// 自定义应用目标文件格式
+const (
+	TargetFormatJSON   = "json"
+	TargetFormatTOML   = "toml"
+	TargetFormatDotenv = "dotenv"
+)
+
+// AppDefinition 自定义应用定义，保存在配置目录的 apps/*.json 中
+// 供应商的字段值保存在 settingsConfig.env 中，切换时按 Targets 写入各个 live 文件
+type AppDefinition struct {
+	Name        string        `json:"name"`
+	DisplayName string        `json:"displayName,omitempty"`
+	Fields      []FormField   `json:"fields"`
+	Targets     []AppTarget   `json:"targets"`
+	Mcp         *AppMcpTarget `json:"mcp,omitempty"`
+}
+
+// AppTarget 一个 live 文件及字段映射
+type AppTarget struct {
+	Path   string            `json:"path"`   // 文件路径，支持 ~/ 开头
+	Format string            `json:"format"` // json、toml 或 dotenv
+	Fields map[string]string `json:"fields"` // 字段 key -> 文件中的位置（json/toml 为点分路径，dotenv 为变量名）
+}
+
+// AppMcpTarget MCP 服务器写入位置
+type AppMcpTarget struct {
+	Path   string `json:"path"`
+	Format string `json:"format"` // json 或 toml
+	Key    string `json:"key"`    // 服务器表所在的点分路径，如 mcpServers
+}
+
+var appNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// reservedAppNames config.json 中的非应用顶层键
+var reservedAppNames = map[string]bool{"version": true, "mcp": true, "preferences": true, "secrets": true}
+
+// AppsDir 自定义应用定义目录
+func (m *Manager) AppsDir() string {
+	return filepath.Join(filepath.Dir(m.configPath), "apps")
+}
+
+// loadCustomApps 加载自定义应用定义，无效的定义打印警告后跳过
+func (m *Manager) loadCustomApps() {
+	m.customApps = nil
+	files, _ := filepath.Glob(filepath.Join(m.AppsDir(), "*.json"))
+	sort.Strings(files)
+	for _, file := range files {
+		def, err := LoadAppDefinition(file)
+		if err == nil && IsBuiltinApp(def.Name) {
+			err = fmt.Errorf("不能覆盖内置应用 %s", def.Name)
+		}
+		if err == nil {
+			if _, dupErr := m.App(def.Name); dupErr == nil {
+				err = fmt.Errorf("应用 %s 已定义", def.Name)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ 加载自定义应用 %s 失败: %v\n", filepath.Base(file), err)
+			continue
+		}
+		m.customApps = append(m.customApps, &declarativeAdapter{def: *def})
+	}
+	sort.Slice(m.customApps, func(i, j int) bool {
+		return m.customApps[i].Name() < m.customApps[j].Name()
+	})
+}
+
+// LoadAppDefinition 读取并校验自定义应用定义
+func LoadAppDefinition(path string) (*AppDefinition, error) {
+	var def AppDefinition
+	if err := utils.ReadJSONFile(path, &def); err != nil {
+		return nil, err
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Validate 校验自定义应用定义
+func (d *AppDefinition) Validate() error {
+	if !appNamePattern.MatchString(d.Name) {
+		return fmt.Errorf("应用名称无效: %q (只能包含小写字母、数字、- 和 _)", d.Name)
+	}
+	if reservedAppNames[d.Name] {
+		return fmt.Errorf("应用名称 %s 为保留字段", d.Name)
+	}
+	if len(d.Fields) == 0 {
+		return fmt.Errorf("至少需要一个字段")
+	}
+
+	fields := make(map[string]bool)
+	for _, f := range d.Fields {
+		if f.Key == "" {
+			return fmt.Errorf("字段 key 不能为空")
+		}
+		if fields[f.Key] {
+			return fmt.Errorf("字段 %s 重复", f.Key)
+		}
+		fields[f.Key] = true
+	}
+
+	if len(d.Targets) == 0 {
+		return fmt.Errorf("至少需要一个目标文件")
+	}
+	for _, t := range d.Targets {
+		if err := validateAppPath(t.Path); err != nil {
+			return err
+		}
+		switch t.Format {
+		case TargetFormatJSON, TargetFormatTOML, TargetFormatDotenv:
+		default:
+			return fmt.Errorf("%s: 不支持的格式 %q (可选 json, toml, dotenv)", t.Path, t.Format)
+		}
+		for key, loc := range t.Fields {
+			if !fields[key] {
+				return fmt.Errorf("%s: 未定义的字段 %s", t.Path, key)
+			}
+			if loc == "" {
+				return fmt.Errorf("%s: 字段 %s 的位置不能为空", t.Path, key)
+			}
+		}
+	}
+
+	if d.Mcp != nil {
+		if err := validateAppPath(d.Mcp.Path); err != nil {
+			return err
+		}
+		if d.Mcp.Format != TargetFormatJSON && d.Mcp.Format != TargetFormatTOML {
+			return fmt.Errorf("MCP: 不支持的格式 %q (可选 json, toml)", d.Mcp.Format)
+		}
+		if d.Mcp.Key == "" {
+			return fmt.Errorf("MCP: key 不能为空")
+		}
+	}
+	return nil
+}
+
+func validateAppPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("目标文件路径不能为空")
+	}
+	if !strings.HasPrefix(path, "~/") && !filepath.IsAbs(path) {
+		return fmt.Errorf("路径必须为绝对路径或以 ~/ 开头: %s", path)
+	}
+	return nil
+}
+
+// resolveAppPath 展开 ~/，使用自定义目录时 ~ 指向该目录（与内置应用一致）
+func (m *Manager) resolveAppPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home := m.customDir
+	if home == "" {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return "", fmt.Errorf("获取用户主目录失败: %w", err)
+		}
+	}
+	return filepath.Join(home, path[2:]), nil
+}
+
+// declarativeAdapter 根据 AppDefinition 实现 AppAdapter
+type declarativeAdapter struct {
+	def AppDefinition
+}
+
+func (a *declarativeAdapter) Name() string { return a.def.Name }
+
+func (a *declarativeAdapter) DisplayName() string {
+	if a.def.DisplayName != "" {
+		return a.def.DisplayName
+	}
+	return a.def.Name
+}
+
+func (a *declarativeAdapter) LivePaths(m *Manager) ([]string, error) {
+	var paths []string
+	for _, t := range a.def.Targets {
+		path, err := m.resolveAppPath(t.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func (a *declarativeAdapter) Stage(m *Manager, ws *writeset.WriteSet, provider *Provider) error {
+	provider, err := m.resolveProviderSecrets(provider)
+	if err != nil {
+		return fmt.Errorf("解密 Token 失败: %w", err)
+	}
+	values := providerFieldValues(provider)
+
+	// 多个目标可能指向同一文件，后一个目标在前一个的结果上修改
+	staged := make(map[string][]byte)
+	for _, t := range a.def.Targets {
+		path, err := m.resolveAppPath(t.Path)
+		if err != nil {
+			return err
+		}
+		existing, ok := staged[path]
+		if !ok {
+			if existing, err = readOptionalFile(path); err != nil {
+				return err
+			}
+		}
+		data, err := applyTarget(t, existing, values)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		staged[path] = data
+		ws.Write(path, data, 0600)
+	}
+	return nil
+}
+
+func (a *declarativeAdapter) Backfill(m *Manager, provider *Provider) (bool, error) {
+	live := make(map[string]string)
+	var keys []string
+	for _, t := range a.def.Targets {
+		path, err := m.resolveAppPath(t.Path)
+		if err != nil {
+			return false, err
+		}
+		if !utils.FileExists(path) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false, fmt.Errorf("读取 %s 失败: %w", path, err)
+		}
+		doc, err := decodeTarget(t.Format, data)
+		if err != nil {
+			// live 文件无法解析，无可回填内容
+			continue
+		}
+		for key, loc := range t.Fields {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+			// 同一字段写入多个文件时，以第一个目标中的值为准
+			if _, seen := live[key]; seen {
+				continue
+			}
+			if v, ok := lookupPath(doc, targetPath(t.Format, loc)); ok {
+				if s := scalarString(v); s != "" {
+					live[key] = s
+				}
+			}
+		}
+	}
+	sort.Strings(keys)
+	return mergeManagedEnv(providerEnvMap(provider), live, keys, m.secretMatches), nil
+}
+
+func (a *declarativeAdapter) ExtractToken(p *Provider) string {
+	return a.fieldValue(p, FieldKindToken)
+}
+
+func (a *declarativeAdapter) ExtractBaseURL(p *Provider) string {
+	return a.fieldValue(p, FieldKindBaseURL)
+}
+
+// fieldValue 返回指定含义字段的值
+func (a *declarativeAdapter) fieldValue(p *Provider, kind string) string {
+	if p == nil {
+		return ""
+	}
+	values := providerFieldValues(p)
+	for _, f := range a.def.Fields {
+		if f.Kind == kind {
+			return values[f.Key]
+		}
+	}
+	return ""
+}
+
+func (a *declarativeAdapter) SupportsMcp() bool { return a.def.Mcp != nil }
+
+func (a *declarativeAdapter) SyncMcp(m *Manager, id string, server map[string]interface{}) error {
+	return a.editMcp(m, func(servers map[string]interface{}) {
+		if server == nil {
+			delete(servers, id)
+		} else {
+			servers[id] = server
+		}
+	})
+}
+
+func (a *declarativeAdapter) SyncAllMcp(m *Manager, servers map[string]interface{}) error {
+	return a.editMcp(m, func(existing map[string]interface{}) {
+		for id := range existing {
+			delete(existing, id)
+		}
+		for id, server := range servers {
+			existing[id] = server
+		}
+	})
+}
+
+// editMcp 修改 MCP 服务器表并立即写入，未配置 MCP 时不做任何操作
+func (a *declarativeAdapter) editMcp(m *Manager, edit func(servers map[string]interface{})) error {
+	if a.def.Mcp == nil {
+		return nil
+	}
+	path, err := m.resolveAppPath(a.def.Mcp.Path)
+	if err != nil {
+		return err
+	}
+	existing, err := readOptionalFile(path)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeTarget(a.def.Mcp.Format, existing)
+	if err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+
+	keyPath := strings.Split(a.def.Mcp.Key, ".")
+	servers, _ := lookupPath(doc, keyPath)
+	table, ok := servers.(map[string]interface{})
+	if !ok {
+		table = make(map[string]interface{})
+	}
+	before := make(map[string]interface{}, len(table))
+	for k, v := range table {
+		before[k] = v
+	}
+	edit(table)
+
+	var data []byte
+	if a.def.Mcp.Format == TargetFormatTOML {
+		data, err = editTOMLData(existing, func(d *tomledit.Document) {
+			for id := range before {
+				if _, ok := table[id]; !ok {
+					d.Delete(childPath(keyPath, id))
+				}
+			}
+			for _, id := range slices.Sorted(maps.Keys(table)) {
+				d.Set(childPath(keyPath, id), table[id])
+			}
+		}, func(config map[string]interface{}) {
+			setPath(config, keyPath, table)
+		})
+	} else {
+		setPath(doc, keyPath, table)
+		data, err = json.MarshalIndent(doc, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("序列化 %s 失败: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := utils.AtomicWriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+func (a *declarativeAdapter) FormFields() []FormField {
+	return append([]FormField{}, a.def.Fields...)
+}
+
+// providerFieldValues 返回自定义应用供应商的字段值（settingsConfig.env 中的字符串）
+func providerFieldValues(p *Provider) map[string]string {
+	values := make(map[string]string)
+	envMap, _ := p.SettingsConfig["env"].(map[string]interface{})
+	for key, val := range envMap {
+		if s, ok := val.(string); ok {
+			values[key] = s
+		}
+	}
+	return values
+}
+
+// applyTarget 将字段值写入目标文件内容：有值时设置，为空时删除，其他内容保持不变
+func applyTarget(t AppTarget, existing []byte, values map[string]string) ([]byte, error) {
+	keys := slices.Sorted(maps.Keys(t.Fields))
+	switch t.Format {
+	case TargetFormatDotenv:
+		updates := make(map[string]string, len(keys))
+		for _, key := range keys {
+			updates[t.Fields[key]] = values[key]
+		}
+		return []byte(updateEnvFile(string(existing), updates)), nil
+
+	case TargetFormatTOML:
+		return editTOMLData(existing, func(d *tomledit.Document) {
+			for _, key := range keys {
+				path := targetPath(t.Format, t.Fields[key])
+				if v := values[key]; v != "" {
+					d.Set(path, v)
+				} else {
+					d.Delete(path)
+				}
+			}
+		}, func(config map[string]interface{}) {
+			for _, key := range keys {
+				path := targetPath(t.Format, t.Fields[key])
+				if v := values[key]; v != "" {
+					setPath(config, path, v)
+				} else {
+					deletePath(config, path)
+				}
+			}
+		})
+
+	default:
+		doc, err := decodeTarget(TargetFormatJSON, existing)
+		if err != nil {
+			return nil, fmt.Errorf("解析失败: %w", err)
+		}
+		for _, key := range keys {
+			path := targetPath(t.Format, t.Fields[key])
+			if v := values[key]; v != "" {
+				setPath(doc, path, v)
+			} else {
+				deletePath(doc, path)
+			}
+		}
+		return json.MarshalIndent(doc, "", "  ")
+	}
+}
+
+// decodeTarget 解析目标文件内容，空内容返回空表
+func decodeTarget(format string, data []byte) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) == 0 {
+		return doc, nil
+	}
+	switch format {
+	case TargetFormatDotenv:
+		vars, _ := parseEnvFile(string(data))
+		for k, v := range vars {
+			doc[k] = v
+		}
+		return doc, nil
+	case TargetFormatTOML:
+		err := toml.Unmarshal(data, &doc)
+		return doc, err
+	default:
+		err := json.Unmarshal(data, &doc)
+		return doc, err
+	}
+}
+
+// targetPath 将字段位置拆分为路径，dotenv 的变量名不拆分
+func targetPath(format, loc string) []string {
+	if format == TargetFormatDotenv {
+		return []string{loc}
+	}
+	return strings.Split(loc, ".")
+}
+
+// editTOMLData 原地修改 TOML 内容，保留注释和格式
+// edit 修改文档，apply 对解析后的内容做同样的修改，两者不一致时回退为整体序列化
+func editTOMLData(existing []byte, edit func(*tomledit.Document), apply func(map[string]interface{})) ([]byte, error) {
+	doc, err := tomledit.Parse(existing)
+	if err != nil {
+		return nil, fmt.Errorf("解析 TOML 失败: %w", err)
+	}
+
+	config := make(map[string]interface{})
+	toml.Unmarshal(existing, &config) // 已通过 Parse 校验
+	apply(config)
+
+	edit(doc)
+	data := doc.Bytes()
+	if !tomledit.Matches(data, config) {
+		if data, err = toml.Marshal(config); err != nil {
+			return nil, fmt.Errorf("序列化 TOML 失败: %w", err)
+		}
+	}
+	return data, nil
+}
+
+// updateEnvFile 更新 .env 内容中的变量：有值时设置，为空时删除，其他行保持不变
+func updateEnvFile(content string, updates map[string]string) string {
+	_, lines := parseEnvFile(content)
+	if content == "" {
+		lines = nil
+	} else if strings.HasSuffix(content, "\n") {
+		lines = lines[:len(lines)-1]
+	}
+
+	written := make(map[string]bool)
+	var out []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		idx := strings.Index(line, "=")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || idx <= 0 {
+			out = append(out, line)
+			continue
+		}
+		key := strings.TrimSpace(line[:idx])
+		val, managed := updates[key]
+		if !managed {
+			out = append(out, line)
+			continue
+		}
+		if val != "" && !written[key] {
+			out = append(out, key+"="+val)
+			written[key] = true
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(updates)) {
+		if val := updates[key]; val != "" && !written[key] {
+			out = append(out, key+"="+val)
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// lookupPath 按路径读取嵌套表中的值
+func lookupPath(doc map[string]interface{}, path []string) (interface{}, bool) {
+	var cur interface{} = doc
+	for _, key := range path {
+		table, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = table[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// setPath 按路径设置嵌套表中的值，中间表不存在时创建
+func setPath(doc map[string]interface{}, path []string, value interface{}) {
+	table := doc
+	for _, key := range path[:len(path)-1] {
+		next, ok := table[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			table[key] = next
+		}
+		table = next
+	}
+	table[path[len(path)-1]] = value
+}
+
+// deletePath 按路径删除嵌套表中的值
+func deletePath(doc map[string]interface{}, path []string) {
+	parent, ok := lookupPath(doc, path[:len(path)-1])
+	if table, isTable := parent.(map[string]interface{}); ok && isTable {
+		delete(table, path[len(path)-1])
+	}
+}
+
+// scalarString 将标量值转换为字符串，表和数组返回空字符串
+func scalarString(v interface{}) string {
+	switch s := v.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return ""
+	case string:
+		return s
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func readOptionalFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	return data, nil
+}
+
+// childPath 返回 path 下的子路径（不修改 path 的底层数组）
+func childPath(path []string, key string) []string {
+	return append(slices.Clip(path), key)
+}
+
+// AddAppProvider 为自定义应用添加供应商，values 为字段 key -> 值
+func (m *Manager) AddAppProvider(appName, name, websiteURL string, values map[string]string) error {
+	defer m.BeginOperation(fmt.Sprintf("添加 %s 配置 '%s'", appName, name))()
+
+	if _, err := m.App(appName); err != nil {
+		return err
+	}
+	if _, exists := m.config.Apps[appName]; !exists {
+		m.config.Apps[appName] = ProviderManager{Providers: make(map[string]Provider)}
+	}
+	app := m.config.Apps[appName]
+	for _, p := range app.Providers {
+		if p.Name == name {
+			return fmt.Errorf("配置 '%s' 已存在", name)
+		}
+	}
+
+	provider := Provider{
+		ID:             uuid.New().String(),
+		Name:           name,
+		SettingsConfig: map[string]interface{}{"env": fieldValuesToEnv(values)},
+		WebsiteURL:     websiteURL,
+		Category:       "custom",
+		CreatedAt:      time.Now().UnixMilli(),
+		SortOrder:      nextSortOrder(app.Providers),
+	}
+	app.Providers[provider.ID] = provider
+
+	isFirstProvider := len(app.Providers) == 1
+	if isFirstProvider {
+		app.Current = provider.ID
+	}
+	m.config.Apps[appName] = app
+
+	if isFirstProvider {
+		if err := m.commitProviderConfig(appName, &provider); err != nil {
+			return fmt.Errorf("写入 live 配置失败: %w", err)
+		}
+		return nil
+	}
+	return m.Save()
+}
+
+// UpdateAppProvider 更新自定义应用的供应商，未出现在 values 中的字段保持不变
+func (m *Manager) UpdateAppProvider(appName, oldName, newName, websiteURL string, values map[string]string) error {
+	defer m.BeginOperation(fmt.Sprintf("更新 %s 配置 '%s'", appName, oldName))()
+
+	id, provider, err := m.findProviderByName(appName, oldName)
+	if err != nil {
+		return err
+	}
+	app := m.config.Apps[appName]
+	if newName != oldName {
+		for _, p := range app.Providers {
+			if p.Name == newName {
+				return fmt.Errorf("配置名称 '%s' 已存在", newName)
+			}
+		}
+	}
+
+	provider.Name = newName
+	if websiteURL != "" {
+		provider.WebsiteURL = websiteURL
+	}
+	envMap := providerEnvMap(&provider)
+	for key, val := range values {
+		if val != "" {
+			envMap[key] = val
+		} else {
+			delete(envMap, key)
+		}
+	}
+	app.Providers[id] = provider
+	m.config.Apps[appName] = app
+
+	if app.Current == id {
+		if err := m.commitProviderConfig(appName, &provider); err != nil {
+			return fmt.Errorf("更新 live 配置失败: %w", err)
+		}
+		return nil
+	}
+	return m.Save()
+}
+
+// AppFieldValues 按字段含义组装自定义应用的字段值（供 config add 等通用参数使用）
+func AppFieldValues(app AppAdapter, token, baseURL, model string) map[string]string {
+	values := make(map[string]string)
+	for _, f := range app.FormFields() {
+		switch f.Kind {
+		case FieldKindToken:
+			values[f.Key] = token
+		case FieldKindBaseURL:
+			values[f.Key] = baseURL
+		case FieldKindModel:
+			values[f.Key] = model
+		}
+	}
+	return values
+}
+
+func fieldValuesToEnv(values map[string]string) map[string]interface{} {
+	env := make(map[string]interface{}, len(values))
+	for key, val := range values {
+		if val != "" {
+			env[key] = val
+		}
+	}
+	return env
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const opencodeDefinition = `{
+  "name": "opencode",
+  "displayName": "OpenCode",
+  "fields": [
+    {"key": "apiKey", "label": "API Key", "kind": "token", "secret": true},
+    {"key": "baseURL", "label": "Base URL", "kind": "baseUrl"},
+    {"key": "model", "label": "模型", "kind": "model"}
+  ],
+  "targets": [
+    {
+      "path": "~/.config/opencode/opencode.json",
+      "format": "json",
+      "fields": {"baseURL": "provider.ccs.options.baseURL", "model": "model"}
+    },
+    {
+      "path": "~/.config/opencode/.env",
+      "format": "dotenv",
+      "fields": {"apiKey": "OPENCODE_API_KEY"}
+    },
+    {
+      "path": "~/.qwen/settings.toml",
+      "format": "toml",
+      "fields": {"model": "model.name"}
+    }
+  ],
+  "mcp": {"path": "~/.config/opencode/opencode.json", "format": "json", "key": "mcp"}
+}`
+
+func newManagerWithApps(t *testing.T, defs map[string]string) (*Manager, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	appsDir := filepath.Join(tmpDir, "apps")
+	if err := os.MkdirAll(appsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range defs {
+		if err := os.WriteFile(filepath.Join(appsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	return manager, tmpDir
+}
+
+func TestLoadCustomApps(t *testing.T) {
+	manager, _ := newManagerWithApps(t, map[string]string{
+		"opencode.json": opencodeDefinition,
+		"claude.json":   strings.Replace(opencodeDefinition, `"opencode"`, `"claude"`, 1),
+		"broken.json":   `{"name": "broken", "fields": []}`,
+	})
+
+	want := []string{"claude", "codex", "gemini", "opencode"}
+	got := manager.AppNames()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("AppNames() = %v, want %v", got, want)
+	}
+	if name := manager.AppDisplayName("opencode"); name != "OpenCode" {
+		t.Errorf("AppDisplayName() = %q", name)
+	}
+	if _, err := manager.App("broken"); err == nil {
+		t.Error("无效的定义应被跳过")
+	}
+}
+
+func TestCustomAppSwitchWritesTargets(t *testing.T) {
+	manager, tmpDir := newManagerWithApps(t, map[string]string{"opencode.json": opencodeDefinition})
+
+	jsonPath := filepath.Join(tmpDir, ".config", "opencode", "opencode.json")
+	envPath := filepath.Join(tmpDir, ".config", "opencode", ".env")
+	tomlPath := filepath.Join(tmpDir, ".qwen", "settings.toml")
+	os.MkdirAll(filepath.Dir(jsonPath), 0755)
+	os.MkdirAll(filepath.Dir(tomlPath), 0755)
+	os.WriteFile(jsonPath, []byte(`{"theme": "dark", "model": "old"}`), 0644)
+	os.WriteFile(envPath, []byte("# keep\nOTHER=1\nOPENCODE_API_KEY=old\n"), 0600)
+	os.WriteFile(tomlPath, []byte("# 注释\n[model]\nname = \"old\" # 行尾注释\n"), 0644)
+
+	if err := manager.AddProviderForApp("opencode", "relay", "", "sk-relay", "https://relay.example.com", "custom", "gpt-5", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddAppProvider("opencode", "local", "", map[string]string{"apiKey": "sk-local"}); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	var doc map[string]interface{}
+	data, _ := os.ReadFile(jsonPath)
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["theme"] != "dark" || doc["model"] != "gpt-5" {
+		t.Errorf("JSON 目标不正确: %s", data)
+	}
+	if v, _ := lookupPath(doc, []string{"provider", "ccs", "options", "baseURL"}); v != "https://relay.example.com" {
+		t.Errorf("嵌套字段未写入: %s", data)
+	}
+	if data, _ := os.ReadFile(envPath); string(data) != "# keep\nOTHER=1\nOPENCODE_API_KEY=sk-relay\n" {
+		t.Errorf(".env 目标不正确: %q", data)
+	}
+	if data, _ := os.ReadFile(tomlPath); string(data) != "# 注释\n[model]\nname = \"gpt-5\" # 行尾注释\n" {
+		t.Errorf("TOML 目标应原地修改: %q", data)
+	}
+
+	// 用户直接修改 live 文件后切换，修改应回填到 relay
+	doc["model"] = "gpt-5-mini"
+	data, _ = json.Marshal(doc)
+	os.WriteFile(jsonPath, data, 0644)
+
+	if err := manager.SwitchProviderForApp("opencode", "local"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	relay, _ := manager.GetProviderForApp("opencode", "relay")
+	if got := relay.SettingsConfig["env"].(map[string]interface{})["model"]; got != "gpt-5-mini" {
+		t.Errorf("回填 model = %v, want gpt-5-mini", got)
+	}
+
+	data, _ = os.ReadFile(jsonPath)
+	doc = nil
+	json.Unmarshal(data, &doc)
+	if _, ok := doc["model"]; ok {
+		t.Errorf("local 没有 model，应从文件中删除: %s", data)
+	}
+	if doc["theme"] != "dark" {
+		t.Errorf("其他字段应保留: %s", data)
+	}
+	if data, _ := os.ReadFile(envPath); !strings.Contains(string(data), "OPENCODE_API_KEY=sk-local") {
+		t.Errorf(".env 未更新: %q", data)
+	}
+}
+
+func TestCustomAppMcpSync(t *testing.T) {
+	manager, tmpDir := newManagerWithApps(t, map[string]string{"opencode.json": opencodeDefinition})
+
+	if names := manager.McpAppNames(); strings.Join(names, ",") != "claude,codex,gemini,opencode" {
+		t.Errorf("McpAppNames() = %v", names)
+	}
+
+	server := McpServer{
+		ID:     "fetch",
+		Name:   "fetch",
+		Server: map[string]interface{}{"type": "stdio", "command": "uvx"},
+	}
+	if err := manager.AddMcpServer(server); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.ToggleMcpApp("fetch", "opencode", true); err != nil {
+		t.Fatalf("ToggleMcpApp() error = %v", err)
+	}
+	if err := manager.SyncMcpServer("fetch"); err != nil {
+		t.Fatalf("SyncMcpServer() error = %v", err)
+	}
+
+	jsonPath := filepath.Join(tmpDir, ".config", "opencode", "opencode.json")
+	var doc map[string]interface{}
+	data, _ := os.ReadFile(jsonPath)
+	json.Unmarshal(data, &doc)
+	if _, ok := lookupPath(doc, []string{"mcp", "fetch"}); !ok {
+		t.Fatalf("MCP 服务器未写入: %s", data)
+	}
+
+	if err := manager.ToggleMcpApp("fetch", "opencode", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SyncAllMcpServersBatch(); err != nil {
+		t.Fatalf("SyncAllMcpServersBatch() error = %v", err)
+	}
+	data, _ = os.ReadFile(jsonPath)
+	doc = nil
+	json.Unmarshal(data, &doc)
+	if _, ok := lookupPath(doc, []string{"mcp", "fetch"}); ok {
+		t.Errorf("停用后 MCP 服务器应被移除: %s", data)
+	}
+}
+
+func TestMcpAppsMarshalKeepsBuiltinKeys(t *testing.T) {
+	data, err := json.Marshal(McpApps{"codex": true, "opencode": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"claude":false,"codex":true,"gemini":false,"opencode":true}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+
+	toml "github.com/pelletier/go-toml/v2"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
+)
+
+// claudeManagedEnvKeys 始终视为由供应商拥有的 Claude 环境变量（切换时移除、回填时读取）
+var claudeManagedEnvKeys = []string{
+	"ANTHROPIC_AUTH_TOKEN",
+	"ANTHROPIC_BASE_URL",
+	"ANTHROPIC_MODEL",
+	"ANTHROPIC_DEFAULT_HAIKU_MODEL",
+	"ANTHROPIC_DEFAULT_SONNET_MODEL",
+	"ANTHROPIC_DEFAULT_OPUS_MODEL",
+	"CLAUDE_CODE_MODEL",
+	"CLAUDE_CODE_MAX_TOKENS",
+}
+
+// geminiManagedEnvKeys 由 CCS 管理、需要回填的 Gemini 环境变量（与 stageGeminiEnvFile 一致）
+var geminiManagedEnvKeys = []string{"GOOGLE_GEMINI_BASE_URL", "GEMINI_API_KEY", "GEMINI_MODEL"}
+
+// codexManagedTopKeys 由 CCS 管理的 Codex config.toml 顶层字段，切换时覆盖、回填时读取
+var codexManagedTopKeys = []string{
+	"model_provider",
+	"model",
+	"model_reasoning_effort",
+	"disable_response_storage",
+}
+
+// credentialKeys 凭据类字段：live 中缺失时不从 provider 中删除，避免误删 Token
+var credentialKeys = map[string]bool{
+	"ANTHROPIC_AUTH_TOKEN":   true,
+	"ANTHROPIC_BASE_URL":     true,
+	"GEMINI_API_KEY":         true,
+	"GOOGLE_GEMINI_BASE_URL": true,
+}
+
+// backfillProviderConfig 读取 live 配置文件，将其中由 CCS 管理的字段合并回 provider.SettingsConfig
+// 返回值表示 provider 是否发生了变化；live 文件不存在时不做任何修改
+func (m *Manager) backfillProviderConfig(appName string, provider *Provider) (bool, error) {
+	if provider == nil {
+		return false, nil
+	}
+
+	app, err := m.App(appName)
+	if err != nil {
+		return false, nil
+	}
+	return app.Backfill(m, provider)
+}
+
+// mergeManagedEnv 按 live 值更新 env 中的受管字段，live 中缺失的非凭据字段会被删除
+// equal 用于比较保存值与 live 值（保存值可能已加密）
+func mergeManagedEnv(envMap map[string]interface{}, live map[string]string, keys []string, equal func(stored, live string) bool) bool {
+	changed := false
+	for _, key := range keys {
+		liveVal := live[key]
+		oldVal, exists := envMap[key]
+		if liveVal != "" {
+			if oldStr, ok := oldVal.(string); !ok || !equal(oldStr, liveVal) {
+				envMap[key] = liveVal
+				changed = true
+			}
+			continue
+		}
+		if exists && !credentialKeys[key] {
+			delete(envMap, key)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// providerEnvMap 获取 provider 的 env map，不存在时创建
+func providerEnvMap(provider *Provider) map[string]interface{} {
+	if provider.SettingsConfig == nil {
+		provider.SettingsConfig = make(map[string]interface{})
+	}
+	envMap, ok := provider.SettingsConfig["env"].(map[string]interface{})
+	if !ok {
+		envMap = make(map[string]interface{})
+		provider.SettingsConfig["env"] = envMap
+	}
+	return envMap
+}
+
+func (m *Manager) backfillClaudeConfig(provider *Provider) (bool, error) {
+	settingsPath, err := m.GetClaudeSettingsPathWithDir()
+	if err != nil {
+		return false, fmt.Errorf("获取 Claude 设置文件路径失败: %w", err)
+	}
+	if !utils.FileExists(settingsPath) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return false, fmt.Errorf("读取 Claude 设置文件失败: %w", err)
+	}
+
+	var settings ClaudeSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		// live 文件已损坏，无可回填内容（写入时同样会忽略解析错误）
+		return false, nil
+	}
+
+	live := make(map[string]string, len(settings.Env))
+	for key, val := range settings.Env {
+		if val != nil {
+			live[key] = fmt.Sprint(val)
+		}
+	}
+
+	// 只回填当前供应商拥有的变量，用户自己添加到 live 的变量不属于供应商
+	keys := append([]string{}, claudeManagedEnvKeys...)
+	for _, key := range m.config.Apps["claude"].LiveEnvKeys {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	changed := mergeManagedEnv(providerEnvMap(provider), live, keys, m.secretMatches)
+
+	oldModel, hasModel := provider.SettingsConfig["model"].(string)
+	if settings.Model != "" {
+		if !hasModel || oldModel != settings.Model {
+			provider.SettingsConfig["model"] = settings.Model
+			changed = true
+		}
+	} else if _, exists := provider.SettingsConfig["model"]; exists {
+		delete(provider.SettingsConfig, "model")
+		changed = true
+	}
+
+	return changed, nil
+}
+
+func (m *Manager) backfillCodexConfig(provider *Provider) (bool, error) {
+	authJsonPath, err := m.GetCodexAuthJsonPathWithDir()
+	if err != nil {
+		return false, fmt.Errorf("获取 Codex auth.json 路径失败: %w", err)
+	}
+	configPath, err := m.GetCodexConfigPathWithDir()
+	if err != nil {
+		return false, fmt.Errorf("获取 Codex config.toml 路径失败: %w", err)
+	}
+
+	if provider.SettingsConfig == nil {
+		provider.SettingsConfig = make(map[string]interface{})
+	}
+	changed := false
+
+	// 1. auth.json → auth.OPENAI_API_KEY
+	if utils.FileExists(authJsonPath) {
+		var auth CodexAuthJson
+		if err := utils.ReadJSONFile(authJsonPath, &auth); err == nil && auth.OpenAIAPIKey != "" {
+			authMap, ok := provider.SettingsConfig["auth"].(map[string]interface{})
+			if !ok {
+				authMap = make(map[string]interface{})
+				provider.SettingsConfig["auth"] = authMap
+			}
+			if old, _ := authMap["OPENAI_API_KEY"].(string); !m.secretMatches(old, auth.OpenAIAPIKey) {
+				authMap["OPENAI_API_KEY"] = auth.OpenAIAPIKey
+				changed = true
+			}
+		}
+	}
+
+	// 2. config.toml → config（仅受管字段）
+	if !utils.FileExists(configPath) {
+		return changed, nil
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return changed, fmt.Errorf("读取 config.toml 失败: %w", err)
+	}
+	var liveConfig map[string]interface{}
+	if err := toml.Unmarshal(data, &liveConfig); err != nil {
+		return changed, nil
+	}
+
+	providerConfig := make(map[string]interface{})
+	if configContent, ok := provider.SettingsConfig["config"].(string); ok && configContent != "" {
+		if err := toml.Unmarshal([]byte(configContent), &providerConfig); err != nil {
+			// provider 中的 TOML 无法解析时不覆盖，避免丢失用户内容
+			return changed, nil
+		}
+	}
+
+	merged := extractCodexManagedConfig(providerConfig, liveConfig)
+	if reflect.DeepEqual(merged, providerConfig) {
+		return changed, nil
+	}
+
+	out, err := toml.Marshal(merged)
+	if err != nil {
+		return changed, fmt.Errorf("序列化 config.toml 失败: %w", err)
+	}
+	provider.SettingsConfig["config"] = string(out)
+	if model, ok := merged["model"].(string); ok && model != "" {
+		provider.SettingsConfig["model"] = model
+	}
+	if reasoning, ok := merged["model_reasoning_effort"].(string); ok && reasoning != "" {
+		provider.SettingsConfig["model_reasoning_effort"] = reasoning
+	}
+	return true, nil
+}
+
+// extractCodexManagedConfig 以 provider 配置为基础，用 live 中的受管字段覆盖
+// 只回填当前 model_provider 对应的 [model_providers.*] 段，其他段（如 mcp_servers）不进入 provider
+func extractCodexManagedConfig(providerConfig, liveConfig map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range providerConfig {
+		result[k] = v
+	}
+
+	for _, key := range codexManagedTopKeys {
+		if val, ok := liveConfig[key]; ok {
+			result[key] = val
+		} else {
+			delete(result, key)
+		}
+	}
+
+	activeName, _ := result["model_provider"].(string)
+	liveProviders, _ := liveConfig["model_providers"].(map[string]interface{})
+	if activeName != "" && liveProviders != nil {
+		if section, ok := liveProviders[activeName]; ok {
+			providers := make(map[string]interface{})
+			if existing, ok := result["model_providers"].(map[string]interface{}); ok {
+				for k, v := range existing {
+					providers[k] = v
+				}
+			}
+			providers[activeName] = section
+			result["model_providers"] = providers
+		}
+	}
+
+	return result
+}
+
+func (m *Manager) backfillGeminiConfig(provider *Provider) (bool, error) {
+	envPath, err := m.GetGeminiEnvPathWithDir()
+	if err != nil {
+		return false, err
+	}
+	if !utils.FileExists(envPath) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(envPath)
+	if err != nil {
+		return false, fmt.Errorf("读取 .env 文件失败: %w", err)
+	}
+
+	live, _ := parseEnvFile(string(data))
+	return mergeManagedEnv(providerEnvMap(provider), live, geminiManagedEnvKeys, m.secretMatches), nil
+}
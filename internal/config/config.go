@@ -5,13 +5,19 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/YangQing-Lin/cc-switch-cli/internal/secrets"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
 )
 
 type Manager struct {
-	config     *MultiAppConfig
-	configPath string
-	customDir  string
+	config         *MultiAppConfig
+	configPath     string
+	customDir      string
+	secretKey      *secrets.Key   // 已解锁的加密密钥（仅保存在内存中）
+	passphraseFunc PassphraseFunc // 获取加密口令的回调
+	customApps     []AppAdapter   // 从 apps/*.json 加载的自定义应用
+	baseData       []byte         // 加载或上次保存时 config.json 的内容，用于检测其他进程的修改
+	inOperation    bool           // 正在记录操作历史（BeginOperation 嵌套时只记录最外层）
 }
 
 func NewManager() (*Manager, error) {
@@ -21,12 +27,17 @@ func NewManager() (*Manager, error) {
 	}
 
 	manager := &Manager{
-		configPath: configPath,
+		configPath:     configPath,
+		passphraseFunc: defaultPassphraseFunc,
 	}
 
+	if err := manager.recoverInterruptedSwitch(); err != nil {
+		return nil, err
+	}
 	if err := manager.Load(); err != nil {
 		return nil, err
 	}
+	manager.loadCustomApps()
 
 	return manager, nil
 }
@@ -42,19 +53,25 @@ func NewManagerWithDir(customDir string) (*Manager, error) {
 
 	configPath := filepath.Join(customDir, "config.json")
 	manager := &Manager{
-		configPath: configPath,
-		customDir:  customDir,
+		configPath:     configPath,
+		customDir:      customDir,
+		passphraseFunc: defaultPassphraseFunc,
 	}
 
+	if err := manager.recoverInterruptedSwitch(); err != nil {
+		return nil, err
+	}
 	if err := manager.Load(); err != nil {
 		return nil, err
 	}
+	manager.loadCustomApps()
 
 	return manager, nil
 }
 
 func (m *Manager) Load() error {
 	if !utils.FileExists(m.configPath) {
+		m.baseData = nil
 		m.createDefaultConfig()
 		return nil
 	}
@@ -63,6 +80,7 @@ func (m *Manager) Load() error {
 	if err != nil {
 		return fmt.Errorf("读取配置文件失败: %w", err)
 	}
+	m.baseData = data
 
 	if m.isEmptyConfig(data) {
 		return m.handleEmptyConfig()
@@ -72,7 +90,11 @@ func (m *Manager) Load() error {
 }
 
 func (m *Manager) createDefaultConfig() {
-	m.config = &MultiAppConfig{
+	m.config = defaultConfig()
+}
+
+func defaultConfig() *MultiAppConfig {
+	return &MultiAppConfig{
 		Version: 2,
 		Apps: map[string]ProviderManager{
 			"claude": {Providers: make(map[string]Provider), Current: ""},
@@ -82,13 +104,19 @@ func (m *Manager) createDefaultConfig() {
 	}
 }
 
+// Save 在文件锁内写入 config.json，期间合并其他进程自加载后的修改
 func (m *Manager) Save() error {
-	dir := filepath.Dir(m.configPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("创建配置目录失败: %w", err)
-	}
-
-	return utils.WriteJSONFile(m.configPath, m.config, 0600)
+	return m.withConfigLock(func() error {
+		data, err := m.encodeConfigForSave()
+		if err != nil {
+			return err
+		}
+		if err := utils.AtomicWriteFile(m.configPath, data, 0600); err != nil {
+			return err
+		}
+		m.baseData = data
+		return nil
+	})
 }
 
 // GetViewMode 获取视图模式偏好
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExtractAppBaseURLFromProvider 按应用类型提取供应商当前使用的 Base URL
+func ExtractAppBaseURLFromProvider(appName string, p *Provider) string {
+	if p == nil {
+		return ""
+	}
+	if appName == "gemini" {
+		baseURL, _, _, _ := ExtractGeminiConfigFromProvider(p)
+		return baseURL
+	}
+	return ExtractBaseURLFromProvider(p)
+}
+
+// ExtractEndpointsFromProvider 返回供应商的全部端点：当前 Base URL 在前，其余自定义端点按 URL 排序
+// 结果已去重（忽略末尾斜杠差异）
+func ExtractEndpointsFromProvider(appName string, p *Provider) []string {
+	if p == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var endpoints []string
+	add := func(endpoint string) {
+		endpoint = strings.TrimSpace(endpoint)
+		key := strings.TrimRight(endpoint, "/")
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		endpoints = append(endpoints, endpoint)
+	}
+
+	add(ExtractAppBaseURLFromProvider(appName, p))
+
+	if p.Meta != nil {
+		custom := make([]string, 0, len(p.Meta.CustomEndpoints))
+		for endpoint := range p.Meta.CustomEndpoints {
+			custom = append(custom, endpoint)
+		}
+		sort.Strings(custom)
+		for _, endpoint := range custom {
+			add(endpoint)
+		}
+	}
+
+	return endpoints
+}
+
+var codexBaseURLLineRegex = regexp.MustCompile(`(base_url\s*=\s*)["'][^"']*["']`)
+
+// NormalizeEndpointURL 校验并规范化端点 URL（去除空白和末尾斜杠）
+func NormalizeEndpointURL(raw string) (string, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+	if trimmed == "" {
+		return "", fmt.Errorf("端点 URL 不能为空")
+	}
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return "", fmt.Errorf("无效的端点 URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("端点 URL 必须以 http:// 或 https:// 开头")
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("端点 URL 缺少主机名")
+	}
+	return trimmed, nil
+}
+
+// findProviderByName 按名称查找供应商，返回 ID 和副本
+func (m *Manager) findProviderByName(appName, name string) (string, Provider, error) {
+	app, exists := m.config.Apps[appName]
+	if !exists {
+		return "", Provider{}, fmt.Errorf("应用 '%s' 不存在", appName)
+	}
+	for id, p := range app.Providers {
+		if p.Name == name {
+			return id, p, nil
+		}
+	}
+	return "", Provider{}, fmt.Errorf("配置 '%s' 不存在", name)
+}
+
+// ensureEndpoint 将端点加入 provider 的自定义端点列表（已存在时不修改）
+func ensureEndpoint(p *Provider, endpoint string, now int64) bool {
+	if p.Meta == nil {
+		p.Meta = &ProviderMeta{}
+	}
+	if p.Meta.CustomEndpoints == nil {
+		p.Meta.CustomEndpoints = make(map[string]CustomEndpoint)
+	}
+	if _, exists := p.Meta.CustomEndpoints[endpoint]; exists {
+		return false
+	}
+	p.Meta.CustomEndpoints[endpoint] = CustomEndpoint{URL: endpoint, AddedAt: now}
+	return true
+}
+
+// AddCustomEndpoint 为供应商添加自定义端点
+func (m *Manager) AddCustomEndpoint(appName, providerName, rawURL string) error {
+	defer m.BeginOperation(fmt.Sprintf("为 %s 配置 '%s' 添加端点 %s", appName, providerName, rawURL))()
+
+	endpoint, err := NormalizeEndpointURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	id, provider, err := m.findProviderByName(appName, providerName)
+	if err != nil {
+		return err
+	}
+
+	if !ensureEndpoint(&provider, endpoint, time.Now().UnixMilli()) {
+		return fmt.Errorf("端点 '%s' 已存在", endpoint)
+	}
+
+	app := m.config.Apps[appName]
+	app.Providers[id] = provider
+	m.config.Apps[appName] = app
+	return m.Save()
+}
+
+// RemoveCustomEndpoint 删除供应商的自定义端点（不影响当前 Base URL）
+func (m *Manager) RemoveCustomEndpoint(appName, providerName, rawURL string) error {
+	defer m.BeginOperation(fmt.Sprintf("删除 %s 配置 '%s' 的端点 %s", appName, providerName, rawURL))()
+
+	endpoint, err := NormalizeEndpointURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	id, provider, err := m.findProviderByName(appName, providerName)
+	if err != nil {
+		return err
+	}
+
+	if provider.Meta == nil || provider.Meta.CustomEndpoints == nil {
+		return fmt.Errorf("端点 '%s' 不存在", endpoint)
+	}
+	if _, exists := provider.Meta.CustomEndpoints[endpoint]; !exists {
+		return fmt.Errorf("端点 '%s' 不存在", endpoint)
+	}
+	delete(provider.Meta.CustomEndpoints, endpoint)
+
+	app := m.config.Apps[appName]
+	app.Providers[id] = provider
+	m.config.Apps[appName] = app
+	return m.Save()
+}
+
+// UseEndpoint 将供应商的 Base URL 切换为指定端点并更新 LastUsed
+// 原 Base URL 会保留在自定义端点列表中；如果该供应商为当前激活配置，同时重写 live 配置
+func (m *Manager) UseEndpoint(appName, providerName, rawURL string) error {
+	defer m.BeginOperation(fmt.Sprintf("将 %s 配置 '%s' 的端点切换为 %s", appName, providerName, rawURL))()
+
+	endpoint, err := NormalizeEndpointURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	id, provider, err := m.findProviderByName(appName, providerName)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	if oldURL := ExtractAppBaseURLFromProvider(appName, &provider); oldURL != "" {
+		if normalized, err := NormalizeEndpointURL(oldURL); err == nil {
+			ensureEndpoint(&provider, normalized, now)
+		}
+	}
+	ensureEndpoint(&provider, endpoint, now)
+
+	if err := setProviderBaseURL(appName, &provider, endpoint); err != nil {
+		return err
+	}
+
+	entry := provider.Meta.CustomEndpoints[endpoint]
+	entry.LastUsed = &now
+	provider.Meta.CustomEndpoints[endpoint] = entry
+
+	app := m.config.Apps[appName]
+	app.Providers[id] = provider
+	m.config.Apps[appName] = app
+
+	if app.Current == id {
+		if err := m.commitProviderConfig(appName, &provider); err != nil {
+			return fmt.Errorf("更新 live 配置失败: %w", err)
+		}
+		return nil
+	}
+
+	return m.Save()
+}
+
+// setProviderBaseURL 修改供应商配置中的 Base URL
+func setProviderBaseURL(appName string, p *Provider, baseURL string) error {
+	if p.SettingsConfig == nil {
+		p.SettingsConfig = make(map[string]interface{})
+	}
+
+	switch appName {
+	case "claude":
+		envMap := providerEnvMap(p)
+		envMap["ANTHROPIC_BASE_URL"] = baseURL
+	case "gemini":
+		envMap := providerEnvMap(p)
+		envMap["GOOGLE_GEMINI_BASE_URL"] = baseURL
+	case "codex":
+		configStr, _ := p.SettingsConfig["config"].(string)
+		if !codexBaseURLLineRegex.MatchString(configStr) {
+			return fmt.Errorf("Codex 配置中未找到 base_url 字段")
+		}
+		replaced := false
+		p.SettingsConfig["config"] = codexBaseURLLineRegex.ReplaceAllStringFunc(configStr, func(match string) string {
+			if replaced {
+				return match
+			}
+			replaced = true
+			prefix := codexBaseURLLineRegex.FindStringSubmatch(match)[1]
+			return prefix + `"` + strings.ReplaceAll(baseURL, `"`, `\"`) + `"`
+		})
+	default:
+		return fmt.Errorf("不支持的应用: %s", appName)
+	}
+
+	return nil
+}
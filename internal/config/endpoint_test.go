@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProviderMetaRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+
+	// GUI 写入的配置：包含自定义端点和 CLI 不认识的 meta 字段
+	content := `{
+  "version": 2,
+  "claude": {
+    "current": "p1",
+    "providers": {
+      "p1": {
+        "id": "p1",
+        "name": "Relay",
+        "settingsConfig": {"env": {"ANTHROPIC_AUTH_TOKEN": "sk-x", "ANTHROPIC_BASE_URL": "https://a.example.com"}},
+        "meta": {
+          "custom_endpoints": {
+            "https://b.example.com": {"url": "https://b.example.com", "addedAt": 1728307200000}
+          },
+          "usage_script": {"enabled": true}
+        }
+      }
+    }
+  }
+}`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	// 触发一次重写
+	if err := manager.MoveProviderForApp("claude", "p1", 1); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	meta := raw["claude"].(map[string]interface{})["providers"].(map[string]interface{})["p1"].(map[string]interface{})["meta"].(map[string]interface{})
+	endpoints, ok := meta["custom_endpoints"].(map[string]interface{})
+	if !ok || endpoints["https://b.example.com"] == nil {
+		t.Errorf("custom_endpoints 丢失: %v", meta)
+	}
+	if meta["usage_script"] == nil {
+		t.Errorf("未知 meta 字段丢失: %v", meta)
+	}
+}
+
+func TestEndpointAddRemoveUse(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "Relay", "", "sk-x", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	if err := manager.AddCustomEndpoint("claude", "Relay", "https://b.example.com/"); err != nil {
+		t.Fatalf("AddCustomEndpoint() error = %v", err)
+	}
+	if err := manager.AddCustomEndpoint("claude", "Relay", "https://b.example.com"); err == nil {
+		t.Error("重复添加端点应该返回错误")
+	}
+	if err := manager.AddCustomEndpoint("claude", "Relay", "ftp://x"); err == nil {
+		t.Error("非 http(s) 端点应该返回错误")
+	}
+
+	if err := manager.UseEndpoint("claude", "Relay", "https://b.example.com"); err != nil {
+		t.Fatalf("UseEndpoint() error = %v", err)
+	}
+
+	p, _ := manager.GetProviderForApp("claude", "Relay")
+	if got := ExtractBaseURLFromProvider(p); got != "https://b.example.com" {
+		t.Errorf("base url = %q, want https://b.example.com", got)
+	}
+	if p.Meta.CustomEndpoints["https://b.example.com"].LastUsed == nil {
+		t.Error("LastUsed 未更新")
+	}
+	if _, ok := p.Meta.CustomEndpoints["https://a.example.com"]; !ok {
+		t.Error("原 Base URL 应保留为自定义端点")
+	}
+
+	// 当前激活配置：live 文件应同步更新
+	var settings ClaudeSettings
+	data, _ := os.ReadFile(filepath.Join(tmpDir, ".claude", "settings.json"))
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("解析 live 文件失败: %v", err)
+	}
+	if settings.Env.Get("ANTHROPIC_BASE_URL") != "https://b.example.com" {
+		t.Errorf("live base url = %q", settings.Env.Get("ANTHROPIC_BASE_URL"))
+	}
+
+	if err := manager.RemoveCustomEndpoint("claude", "Relay", "https://a.example.com"); err != nil {
+		t.Fatalf("RemoveCustomEndpoint() error = %v", err)
+	}
+	if err := manager.RemoveCustomEndpoint("claude", "Relay", "https://a.example.com"); err == nil {
+		t.Error("删除不存在的端点应该返回错误")
+	}
+}
+
+func TestUseEndpointCodex(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("codex", "Relay", "", "sk-x", "https://a.example.com/v1", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	if err := manager.UseEndpoint("codex", "Relay", "https://b.example.com/v1"); err != nil {
+		t.Fatalf("UseEndpoint() error = %v", err)
+	}
+	p, _ := manager.GetProviderForApp("codex", "Relay")
+	if got := ExtractBaseURLFromProvider(p); got != "https://b.example.com/v1" {
+		t.Errorf("base url = %q", got)
+	}
+}
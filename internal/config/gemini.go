@@ -9,15 +9,21 @@ import (
 	"time"
 
 	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/writeset"
 	"github.com/google/uuid"
 )
 
-// writeGeminiConfig writes Gemini configuration to ~/.gemini/.env and settings.json
-func (m *Manager) writeGeminiConfig(provider *Provider) error {
+// stageGeminiConfig stages Gemini configuration for ~/.gemini/.env and settings.json
+func (m *Manager) stageGeminiConfig(ws *writeset.WriteSet, provider *Provider) error {
 	if provider == nil {
 		return fmt.Errorf("provider 不能为空")
 	}
 
+	provider, err := m.resolveProviderSecrets(provider)
+	if err != nil {
+		return fmt.Errorf("解密 Token 失败: %w", err)
+	}
+
 	// 检测认证类型
 	authType := DetectGeminiAuthType(provider)
 
@@ -25,12 +31,12 @@ func (m *Manager) writeGeminiConfig(provider *Provider) error {
 	envMap := NormalizeGeminiEnv(provider)
 
 	// 写入 .env 文件
-	if err := m.writeGeminiEnvFile(envMap); err != nil {
+	if err := m.stageGeminiEnvFile(ws, envMap); err != nil {
 		return fmt.Errorf("写入 .env 文件失败: %w", err)
 	}
 
 	// 写入 settings.json 文件
-	if err := m.writeGeminiSettingsFile(authType); err != nil {
+	if err := m.stageGeminiSettingsFile(ws, authType); err != nil {
 		return fmt.Errorf("写入 settings.json 失败: %w", err)
 	}
 
@@ -141,14 +147,23 @@ func parseEnvFile(content string) (envVars map[string]string, lines []string) {
 	return
 }
 
-// writeGeminiEnvFile 写入 Gemini .env 文件（增量更新，只修改三个特定变量）
+// writeGeminiEnvFile 立即写入 Gemini .env 文件
 func (m *Manager) writeGeminiEnvFile(envMap map[string]string) error {
+	ws := m.newWriteSet()
+	if err := m.stageGeminiEnvFile(ws, envMap); err != nil {
+		return err
+	}
+	return ws.Commit()
+}
+
+// stageGeminiEnvFile 暂存 Gemini .env 文件（增量更新，只修改三个特定变量）
+func (m *Manager) stageGeminiEnvFile(ws *writeset.WriteSet, envMap map[string]string) error {
 	envPath, err := m.GetGeminiEnvPathWithDir()
 	if err != nil {
 		return err
 	}
 
-	// 确保目录存在
+	// 确保目录存在（Gemini 目录仅当前用户可访问）
 	dir := filepath.Dir(envPath)
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return fmt.Errorf("创建目录失败: %w", err)
@@ -242,12 +257,13 @@ func (m *Manager) writeGeminiEnvFile(envMap map[string]string) error {
 		content += "\n"
 	}
 
-	// 原子写入，权限 0600
-	return utils.AtomicWriteFile(envPath, []byte(content), 0600)
+	// 权限 0600
+	ws.Write(envPath, []byte(content), 0600)
+	return nil
 }
 
-// writeGeminiSettingsFile 写入 Gemini settings.json 文件
-func (m *Manager) writeGeminiSettingsFile(authType GeminiAuthType) error {
+// stageGeminiSettingsFile 暂存 Gemini settings.json 文件
+func (m *Manager) stageGeminiSettingsFile(ws *writeset.WriteSet, authType GeminiAuthType) error {
 	settingsPath, err := m.GetGeminiSettingsPathWithDir()
 	if err != nil {
 		return err
@@ -292,8 +308,8 @@ func (m *Manager) writeGeminiSettingsFile(authType GeminiAuthType) error {
 		settings.Extra = make(map[string]interface{})
 	}
 
-	// 原子写入 JSON，权限 0600
-	return utils.WriteJSONFile(settingsPath, settings, 0600)
+	// 权限 0600
+	return ws.WriteJSON(settingsPath, settings, 0600)
 }
 
 // ExtractGeminiConfigFromProvider extracts Gemini configuration fields from a Provider
@@ -324,6 +340,8 @@ func ExtractGeminiConfigFromProvider(p *Provider) (baseURL, apiKey, model string
 
 // AddGeminiProvider adds a new Gemini provider configuration
 func (m *Manager) AddGeminiProvider(name, baseURL, apiKey, model string, authType GeminiAuthType) error {
+	defer m.BeginOperation(fmt.Sprintf("添加 gemini 配置 '%s'", name))()
+
 	app, exists := m.config.Apps["gemini"]
 	if !exists {
 		return fmt.Errorf("应用 'gemini' 不存在")
@@ -374,12 +392,9 @@ func (m *Manager) AddGeminiProvider(name, baseURL, apiKey, model string, authTyp
 	if len(app.Providers) == 1 {
 		app.Current = provider.ID
 		m.config.Apps["gemini"] = app
-		if err := m.writeGeminiConfig(&provider); err != nil {
+		if err := m.commitProviderConfig("gemini", &provider); err != nil {
 			return fmt.Errorf("写入 live 配置失败: %w", err)
 		}
-		if err := m.Save(); err != nil {
-			return fmt.Errorf("保存配置失败: %w", err)
-		}
 	}
 
 	return nil
@@ -387,6 +402,8 @@ func (m *Manager) AddGeminiProvider(name, baseURL, apiKey, model string, authTyp
 
 // UpdateGeminiProvider updates an existing Gemini provider configuration
 func (m *Manager) UpdateGeminiProvider(oldName, newName, baseURL, apiKey, model string, authType GeminiAuthType) error {
+	defer m.BeginOperation(fmt.Sprintf("更新 gemini 配置 '%s'", oldName))()
+
 	app, exists := m.config.Apps["gemini"]
 	if !exists {
 		return fmt.Errorf("应用 'gemini' 不存在")
@@ -446,9 +463,10 @@ func (m *Manager) UpdateGeminiProvider(oldName, newName, baseURL, apiKey, model
 
 	// 如果修改的是当前配置，同时更新 live 文件
 	if app.Current == targetID {
-		if err := m.writeGeminiConfig(oldProvider); err != nil {
+		if err := m.commitProviderConfig("gemini", oldProvider); err != nil {
 			return fmt.Errorf("写入 live 配置失败: %w", err)
 		}
+		return nil
 	}
 
 	return m.Save()
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/history"
+)
+
+// historyStore 操作历史，位于配置目录的 history/ 下，与切换共用写入日志
+func (m *Manager) historyStore() *history.Store {
+	return history.New(filepath.Join(filepath.Dir(m.configPath), "history"), m.journalPath())
+}
+
+// historyPaths 返回操作历史需要记录的文件：config.json、live 文件以及 MCP 配置文件
+func (m *Manager) historyPaths() []string {
+	paths := m.WatchedPaths()
+	if codexConfig, err := m.GetCodexConfigPathWithDir(); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(codexConfig), "mcp.json"))
+	}
+	for _, app := range m.customApps {
+		if d, ok := app.(*declarativeAdapter); ok && d.def.Mcp != nil {
+			if path, err := m.resolveAppPath(d.def.Mcp.Path); err == nil && !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// BeginOperation 开始记录一次修改操作，返回的函数需在操作结束后调用：
+// 期间 config.json、live 文件及 extraPaths 的变化会作为一条历史记录保存，没有变化则不记录
+// 嵌套调用时只有最外层生效
+func (m *Manager) BeginOperation(summary string, extraPaths ...string) func() {
+	if m.inOperation {
+		return func() {}
+	}
+	m.inOperation = true
+
+	paths := append(m.historyPaths(), extraPaths...)
+	before := history.Capture(paths)
+	return func() {
+		m.inOperation = false
+		changes := history.Diff(before, history.Capture(paths))
+		if _, err := m.historyStore().Record(summary, changes); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ 记录操作历史失败: %v\n", err)
+		}
+	}
+}
+
+// History 返回操作历史，按时间从早到晚排列
+func (m *Manager) History() ([]history.Entry, error) {
+	return m.historyStore().List()
+}
+
+// Undo 撤销最近 n 次操作，恢复 config.json 和 live 文件并重新加载配置
+// 文件在操作之后又被修改时返回 history.ErrDiverged，force 为 true 时仍然覆盖
+func (m *Manager) Undo(n int, force bool) ([]history.Entry, error) {
+	var entries []history.Entry
+	err := m.withConfigLock(func() error {
+		var err error
+		entries, err = m.historyStore().Undo(n, force)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, m.Load()
+}
+
+// Redo 重做最近撤销的 n 次操作
+func (m *Manager) Redo(n int, force bool) ([]history.Entry, error) {
+	var entries []history.Entry
+	err := m.withConfigLock(func() error {
+		var err error
+		entries, err = m.historyStore().Redo(n, force)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, m.Load()
+}
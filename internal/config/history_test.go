@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUndoSwitchRestoresLiveSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "A", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.AddProviderForApp("claude", "B", "", "sk-b", "https://b.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SwitchProviderForApp("claude", "B"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 两次添加（A 自动成为当前配置）和一次切换
+	entries, err := manager.History()
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if n := len(entries); n != 3 || entries[n-1].Summary != "切换 claude 到 'B'" {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	if _, err := manager.Undo(1, false); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if got := manager.GetCurrentProviderForApp("claude"); got == nil || got.Name != "A" {
+		t.Errorf("撤销后当前配置应为 A, got %v", got)
+	}
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("读取 live 文件失败: %v", err)
+	}
+	var settings ClaudeSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("解析 live 文件失败: %v", err)
+	}
+	if got := settings.Env.Get("ANTHROPIC_BASE_URL"); got != "https://a.example.com" {
+		t.Errorf("撤销后 live 文件应恢复, base url = %v", got)
+	}
+
+	if _, err := manager.Redo(1, false); err != nil {
+		t.Fatalf("Redo() error = %v", err)
+	}
+	if got := manager.GetCurrentProviderForApp("claude"); got == nil || got.Name != "B" {
+		t.Errorf("重做后当前配置应为 B, got %v", got)
+	}
+}
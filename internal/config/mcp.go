@@ -124,16 +124,15 @@ func (m *Manager) ToggleMcpApp(serverID, appName string, enabled bool) error {
 	}
 
 	// 更新应用启用状态
-	switch strings.ToLower(appName) {
-	case "claude":
-		server.Apps.Claude = enabled
-	case "codex":
-		server.Apps.Codex = enabled
-	case "gemini":
-		server.Apps.Gemini = enabled
-	default:
+	appName = strings.ToLower(appName)
+	app, err := m.App(appName)
+	if err != nil || !app.SupportsMcp() {
 		return fmt.Errorf("未知的应用名称: %s", appName)
 	}
+	if server.Apps == nil {
+		server.Apps = McpApps{}
+	}
+	server.Apps[appName] = enabled
 
 	// 保存更新
 	m.config.Mcp.Servers[serverID] = server
@@ -218,7 +217,7 @@ func GetMcpPresets() []McpServer {
 				"command": "uvx",
 				"args":    []interface{}{"mcp-server-fetch"},
 			},
-			Apps: McpApps{Claude: false, Codex: false, Gemini: false},
+			Apps: McpApps{},
 		},
 		{
 			ID:          "time",
@@ -232,7 +231,7 @@ func GetMcpPresets() []McpServer {
 				"command": "npx",
 				"args":    []interface{}{"-y", "@modelcontextprotocol/server-time"},
 			},
-			Apps: McpApps{Claude: false, Codex: false, Gemini: false},
+			Apps: McpApps{},
 		},
 		{
 			ID:          "memory",
@@ -246,7 +245,7 @@ func GetMcpPresets() []McpServer {
 				"command": "npx",
 				"args":    []interface{}{"-y", "@modelcontextprotocol/server-memory"},
 			},
-			Apps: McpApps{Claude: false, Codex: false, Gemini: false},
+			Apps: McpApps{},
 		},
 		{
 			ID:          "sequential-thinking",
@@ -260,7 +259,7 @@ func GetMcpPresets() []McpServer {
 				"command": "npx",
 				"args":    []interface{}{"-y", "@modelcontextprotocol/server-sequential-thinking"},
 			},
-			Apps: McpApps{Claude: false, Codex: false, Gemini: false},
+			Apps: McpApps{},
 		},
 		{
 			ID:          "filesystem",
@@ -274,7 +273,7 @@ func GetMcpPresets() []McpServer {
 				"command": "npx",
 				"args":    []interface{}{"-y", "@modelcontextprotocol/server-filesystem", "/tmp"},
 			},
-			Apps: McpApps{Claude: false, Codex: false, Gemini: false},
+			Apps: McpApps{},
 		},
 	}
 }
@@ -7,8 +7,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/YangQing-Lin/cc-switch-cli/internal/tomledit"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
-	"github.com/pelletier/go-toml/v2"
 )
 
 // SyncMcpToClaud 同步单个 MCP 服务器到 Claude
@@ -22,7 +22,7 @@ func (m *Manager) SyncMcpToClaud(serverID string) error {
 	}
 
 	// 如果未启用 Claude，移除配置
-	if !server.Apps.Claude {
+	if !server.Apps["claude"] {
 		return m.RemoveMcpFromClaude(serverID)
 	}
 
@@ -127,7 +127,7 @@ func (m *Manager) SyncMcpToCodex(serverID string) error {
 	}
 
 	// 如果未启用 Codex，移除配置
-	if !server.Apps.Codex {
+	if !server.Apps["codex"] {
 		return m.RemoveMcpFromCodex(serverID)
 	}
 
@@ -142,43 +142,17 @@ func (m *Manager) SyncMcpToCodex(serverID string) error {
 		return fmt.Errorf("创建 Codex 配置目录失败: %w", err)
 	}
 
-	// 读取现有配置
-	var config map[string]interface{}
-	if utils.FileExists(configPath) {
-		data, err := os.ReadFile(configPath)
-		if err != nil {
-			return fmt.Errorf("读取 Codex 配置失败: %w", err)
+	path := []string{"mcp_servers", server.ID}
+	return editCodexConfigFile(configPath, func(doc *tomledit.Document) {
+		doc.Set(path, server.Server)
+	}, func(config map[string]interface{}) {
+		mcpServers, ok := config["mcp_servers"].(map[string]interface{})
+		if !ok {
+			mcpServers = make(map[string]interface{})
 		}
-		if err := toml.Unmarshal(data, &config); err != nil {
-			return fmt.Errorf("解析 Codex 配置失败: %w", err)
-		}
-	} else {
-		config = make(map[string]interface{})
-	}
-
-	// 获取或创建 mcp_servers
-	var mcpServers map[string]interface{}
-	if existingMcp, ok := config["mcp_servers"].(map[string]interface{}); ok {
-		mcpServers = existingMcp
-	} else {
-		mcpServers = make(map[string]interface{})
-	}
-
-	// 添加或更新服务器配置
-	mcpServers[server.ID] = server.Server
-	config["mcp_servers"] = mcpServers
-
-	// 写入配置
-	data, err := toml.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("序列化 Codex 配置失败: %w", err)
-	}
-
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		return fmt.Errorf("写入 Codex 配置失败: %w", err)
-	}
-
-	return nil
+		mcpServers[server.ID] = server.Server
+		config["mcp_servers"] = mcpServers
+	})
 }
 
 // RemoveMcpFromCodex 从 Codex 移除 MCP 服务器
@@ -192,27 +166,31 @@ func (m *Manager) RemoveMcpFromCodex(serverID string) error {
 		return nil // 配置文件不存在，无需移除
 	}
 
-	// 读取现有配置
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return fmt.Errorf("读取 Codex 配置失败: %w", err)
-	}
-
-	var config map[string]interface{}
-	if err := toml.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("解析 Codex 配置失败: %w", err)
-	}
+	return editCodexConfigFile(configPath, func(doc *tomledit.Document) {
+		doc.Delete([]string{"mcp_servers", serverID})
+	}, func(config map[string]interface{}) {
+		if mcpServers, ok := config["mcp_servers"].(map[string]interface{}); ok {
+			delete(mcpServers, serverID)
+		}
+	})
+}
 
-	// 移除 MCP 服务器
-	if mcpServers, ok := config["mcp_servers"].(map[string]interface{}); ok {
-		delete(mcpServers, serverID)
-		config["mcp_servers"] = mcpServers
+// editCodexConfigFile 原地修改 Codex config.toml，保留注释和格式
+// edit 修改文档，apply 对解析后的配置做同样的修改，用于校验原地修改的结果；
+// 两者不一致时回退为整体序列化
+func editCodexConfigFile(configPath string, edit func(*tomledit.Document), apply func(map[string]interface{})) error {
+	var existing []byte
+	if utils.FileExists(configPath) {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("读取 Codex 配置失败: %w", err)
+		}
+		existing = data
 	}
 
-	// 写入配置
-	data, err = toml.Marshal(config)
+	data, err := editTOMLData(existing, edit, apply)
 	if err != nil {
-		return fmt.Errorf("序列化 Codex 配置失败: %w", err)
+		return fmt.Errorf("修改 Codex 配置失败: %w", err)
 	}
 
 	if err := os.WriteFile(configPath, data, 0600); err != nil {
@@ -233,7 +211,7 @@ func (m *Manager) SyncMcpToGemini(serverID string) error {
 	}
 
 	// 如果未启用 Gemini，移除配置
-	if !server.Apps.Gemini {
+	if !server.Apps["gemini"] {
 		return m.RemoveMcpFromGemini(serverID)
 	}
 
@@ -324,37 +302,16 @@ func (m *Manager) SyncMcpServer(serverID string) error {
 	}
 
 	var errs []error
-
-	// 同步到 Claude
-	if server.Apps.Claude {
-		if err := m.SyncMcpToClaud(serverID); err != nil {
-			errs = append(errs, fmt.Errorf("同步到 Claude 失败: %w", err))
-		}
-	} else {
-		if err := m.RemoveMcpFromClaude(serverID); err != nil {
-			errs = append(errs, fmt.Errorf("从 Claude 移除失败: %w", err))
+	for _, app := range m.Apps() {
+		if !app.SupportsMcp() {
+			continue
 		}
-	}
-
-	// 同步到 Codex
-	if server.Apps.Codex {
-		if err := m.SyncMcpToCodex(serverID); err != nil {
-			errs = append(errs, fmt.Errorf("同步到 Codex 失败: %w", err))
-		}
-	} else {
-		if err := m.RemoveMcpFromCodex(serverID); err != nil {
-			errs = append(errs, fmt.Errorf("从 Codex 移除失败: %w", err))
-		}
-	}
-
-	// 同步到 Gemini
-	if server.Apps.Gemini {
-		if err := m.SyncMcpToGemini(serverID); err != nil {
-			errs = append(errs, fmt.Errorf("同步到 Gemini 失败: %w", err))
-		}
-	} else {
-		if err := m.RemoveMcpFromGemini(serverID); err != nil {
-			errs = append(errs, fmt.Errorf("从 Gemini 移除失败: %w", err))
+		if server.Apps[app.Name()] {
+			if err := app.SyncMcp(m, serverID, server.Server); err != nil {
+				errs = append(errs, fmt.Errorf("同步到 %s 失败: %w", app.DisplayName(), err))
+			}
+		} else if err := app.SyncMcp(m, serverID, nil); err != nil {
+			errs = append(errs, fmt.Errorf("从 %s 移除失败: %w", app.DisplayName(), err))
 		}
 	}
 
@@ -388,41 +345,24 @@ func (m *Manager) SyncAllMcpServers() error {
 func (m *Manager) SyncAllMcpServersBatch() error {
 	m.ensureMcpRoot()
 
-	// 1. 构建每个应用的 MCP 服务器映射
-	claudeServers := make(map[string]interface{})
-	codexServers := make(map[string]interface{})
-	geminiServers := make(map[string]interface{})
-
-	for id, server := range m.config.Mcp.Servers {
-		if server.Apps.Claude {
-			claudeServers[id] = server.Server
+	var errs []error
+	for _, app := range m.Apps() {
+		if !app.SupportsMcp() {
+			continue
 		}
-		if server.Apps.Codex {
-			codexServers[id] = server.Server
+
+		// 每个应用只写入一次
+		servers := make(map[string]interface{})
+		for id, server := range m.config.Mcp.Servers {
+			if server.Apps[app.Name()] {
+				servers[id] = server.Server
+			}
 		}
-		if server.Apps.Gemini {
-			geminiServers[id] = server.Server
+		if err := app.SyncAllMcp(m, servers); err != nil {
+			errs = append(errs, fmt.Errorf("同步到 %s 失败: %w", app.DisplayName(), err))
 		}
 	}
 
-	// 2. 一次性写入每个应用的配置
-	var errs []error
-
-	// 同步到 Claude
-	if err := m.syncMcpToClaudeBatch(claudeServers); err != nil {
-		errs = append(errs, fmt.Errorf("同步到 Claude 失败: %w", err))
-	}
-
-	// 同步到 Codex
-	if err := m.syncMcpToCodexBatch(codexServers); err != nil {
-		errs = append(errs, fmt.Errorf("同步到 Codex 失败: %w", err))
-	}
-
-	// 同步到 Gemini
-	if err := m.syncMcpToGeminiBatch(geminiServers); err != nil {
-		errs = append(errs, fmt.Errorf("同步到 Gemini 失败: %w", err))
-	}
-
 	if len(errs) > 0 {
 		return fmt.Errorf("部分同步失败: %w", errors.Join(errs...))
 	}
@@ -235,11 +235,7 @@ func TestMcpServerCRUD(t *testing.T) {
 			"command": "npx",
 			"args":    []interface{}{"@modelcontextprotocol/server-test"},
 		},
-		Apps: McpApps{
-			Claude: true,
-			Codex:  false,
-			Gemini: false,
-		},
+		Apps: McpApps{"claude": true},
 	}
 
 	if err := manager.AddMcpServer(server); err != nil {
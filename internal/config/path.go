@@ -57,6 +57,36 @@ func (m *Manager) GetClaudeSettingsPathWithDir() (string, error) {
 	return settingsPath, nil
 }
 
+// Claude 设置写入范围
+const (
+	ClaudeScopeGlobal  = "global"  // ~/.claude/settings.json
+	ClaudeScopeProject = "project" // ./.claude/settings.json
+	ClaudeScopeLocal   = "local"   // ./.claude/settings.local.json（Git 忽略）
+)
+
+// ClaudeScopes 所有可用的 Claude 设置范围，按切换顺序排列
+var ClaudeScopes = []string{ClaudeScopeGlobal, ClaudeScopeProject, ClaudeScopeLocal}
+
+// GetClaudeSettingsPathForScope 返回指定范围的 Claude 设置文件路径，项目范围基于当前工作目录
+func (m *Manager) GetClaudeSettingsPathForScope(scope string) (string, error) {
+	switch scope {
+	case "", ClaudeScopeGlobal:
+		return m.GetClaudeSettingsPathWithDir()
+	case ClaudeScopeProject, ClaudeScopeLocal:
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("获取当前目录失败: %w", err)
+		}
+		fileName := "settings.json"
+		if scope == ClaudeScopeLocal {
+			fileName = "settings.local.json"
+		}
+		return filepath.Join(cwd, ".claude", fileName), nil
+	default:
+		return "", fmt.Errorf("不支持的范围: %s (可选 global, project, local)", scope)
+	}
+}
+
 func GetCodexConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -82,6 +82,12 @@ func (m *Manager) AddProviderWithWebsite(appName, name, websiteURL, apiToken, ba
 }
 
 func (m *Manager) AddProviderForApp(appName, name, websiteURL, apiToken, baseURL, category, claudeModel, defaultHaikuModel, defaultSonnetModel, defaultOpusModel string) error {
+	defer m.BeginOperation(fmt.Sprintf("添加 %s 配置 '%s'", appName, name))()
+
+	if app, err := m.App(appName); err == nil && !IsBuiltinApp(appName) {
+		return m.AddAppProvider(appName, name, websiteURL, AppFieldValues(app, apiToken, baseURL, claudeModel))
+	}
+
 	if _, exists := m.config.Apps[appName]; !exists {
 		m.config.Apps[appName] = ProviderManager{
 			Providers: make(map[string]Provider),
@@ -171,20 +177,19 @@ func (m *Manager) AddProviderForApp(appName, name, websiteURL, apiToken, baseURL
 
 	m.config.Apps[appName] = app
 
-	if err := m.Save(); err != nil {
-		return err
-	}
-
 	if isFirstProvider {
-		if err := m.writeProviderConfig(appName, &provider); err != nil {
-			return fmt.Errorf("保存配置成功，但写入 live 配置失败: %w", err)
+		if err := m.commitProviderConfig(appName, &provider); err != nil {
+			return fmt.Errorf("写入 live 配置失败: %w", err)
 		}
+		return nil
 	}
 
-	return nil
+	return m.Save()
 }
 
 func (m *Manager) AddProviderDirect(appName string, provider Provider) error {
+	defer m.BeginOperation(fmt.Sprintf("添加 %s 配置 '%s'", appName, provider.Name))()
+
 	if _, exists := m.config.Apps[appName]; !exists {
 		m.config.Apps[appName] = ProviderManager{
 			Providers: make(map[string]Provider),
@@ -229,6 +234,8 @@ func (m *Manager) DeleteProvider(name string) error {
 }
 
 func (m *Manager) DeleteProviderForApp(appName, name string) error {
+	defer m.BeginOperation(fmt.Sprintf("删除 %s 配置 '%s'", appName, name))()
+
 	app, exists := m.config.Apps[appName]
 	if !exists {
 		return fmt.Errorf("应用 '%s' 不存在", appName)
@@ -301,6 +308,7 @@ func (m *Manager) MoveProviderForApp(appName, providerID string, direction int)
 	if direction == 0 {
 		return nil
 	}
+	defer m.BeginOperation(fmt.Sprintf("调整 %s 配置排序", appName))()
 
 	app, exists := m.config.Apps[appName]
 	if !exists {
@@ -362,6 +370,7 @@ func (m *Manager) GetConfig() (*MultiAppConfig, error) {
 	configCopy := &MultiAppConfig{
 		Version: m.config.Version,
 		Apps:    make(map[string]ProviderManager),
+		Secrets: m.config.Secrets, // 保留加密参数，否则导出的密文无法解密
 	}
 
 	for appName, appConfig := range m.config.Apps {
@@ -387,6 +396,12 @@ func (m *Manager) UpdateProviderWithWebsite(appName, oldName, newName, websiteUR
 }
 
 func (m *Manager) UpdateProviderForApp(appName, oldName, newName, websiteURL, apiToken, baseURL, category, claudeModel, defaultHaikuModel, defaultSonnetModel, defaultOpusModel string) error {
+	defer m.BeginOperation(fmt.Sprintf("更新 %s 配置 '%s'", appName, oldName))()
+
+	if app, err := m.App(appName); err == nil && !IsBuiltinApp(appName) {
+		return m.UpdateAppProvider(appName, oldName, newName, websiteURL, AppFieldValues(app, apiToken, baseURL, claudeModel))
+	}
+
 	app, exists := m.config.Apps[appName]
 	if !exists {
 		return fmt.Errorf("应用 '%s' 不存在", appName)
@@ -494,9 +509,10 @@ func (m *Manager) UpdateProviderForApp(appName, oldName, newName, websiteURL, ap
 	m.config.Apps[appName] = app
 
 	if app.Current == targetID {
-		if err := m.writeProviderConfig(appName, &targetProvider); err != nil {
+		if err := m.commitProviderConfig(appName, &targetProvider); err != nil {
 			return fmt.Errorf("更新 live 配置失败: %w", err)
 		}
+		return nil
 	}
 
 	return m.Save()
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/lock"
+)
+
+// configLockTimeout 等待其他进程释放 config.json 写锁的最长时间
+const configLockTimeout = 10 * time.Second
+
+// ErrConfigConflict 自加载后 config.json 被其他进程修改，且与本次修改冲突
+var ErrConfigConflict = errors.New("配置文件已被其他进程修改")
+
+// ConfigLockPath 返回 config.json 写锁文件路径
+func (m *Manager) ConfigLockPath() string {
+	return m.configPath + ".lock"
+}
+
+// withConfigLock 持有 config.json 的 OS 咨询锁执行 fn，保证读取-合并-写入不被其他进程打断
+func (m *Manager) withConfigLock(fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(m.configPath), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+	fl := lock.NewFileLock(m.ConfigLockPath())
+	if err := fl.Lock(configLockTimeout); err != nil {
+		return fmt.Errorf("获取配置文件锁失败: %w", err)
+	}
+	defer fl.Unlock()
+	return fn()
+}
+
+// encodeConfigForSave 加密新增明文、合并其他进程自加载后的修改，返回待写入的内容
+// 必须在 withConfigLock 内调用
+func (m *Manager) encodeConfigForSave() ([]byte, error) {
+	if err := m.prepareSecretsForSave(); err != nil {
+		return nil, err
+	}
+	if err := m.mergeExternalChanges(); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(m.config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化 JSON 失败: %w", err)
+	}
+	return data, nil
+}
+
+// mergeExternalChanges 磁盘上的 config.json 与加载时不同时做三方合并：
+// 以加载时的内容为基准，双方只改了不同字段时自动合并，同一字段改成不同值时报错
+func (m *Manager) mergeExternalChanges() error {
+	theirsData, err := os.ReadFile(m.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// 文件被删除，直接写入当前内容
+			return nil
+		}
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	if string(theirsData) == string(m.baseData) {
+		return nil
+	}
+
+	theirs, err := normalizeConfigJSON(theirsData)
+	if err != nil {
+		return fmt.Errorf("%w，且新内容无法解析: %v", ErrConfigConflict, err)
+	}
+	var base interface{}
+	if m.baseData != nil {
+		base, err = normalizeConfigJSON(m.baseData)
+	}
+	if m.baseData == nil || err != nil {
+		// 加载时文件不存在或无法解析，以默认配置为基准
+		base, err = toGenericJSON(defaultConfig())
+		if err != nil {
+			return err
+		}
+	}
+	ours, err := toGenericJSON(m.config)
+	if err != nil {
+		return err
+	}
+
+	merged, _, err := mergeJSONValue(base, ours, theirs, true, true, true, "")
+	if err != nil {
+		return fmt.Errorf("%w，且与本次修改冲突 (%v)，请重新执行", ErrConfigConflict, err)
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("序列化 JSON 失败: %w", err)
+	}
+	cfg := &MultiAppConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("解析合并后的配置失败: %w", err)
+	}
+	m.config = cfg
+	return nil
+}
+
+// mergeJSONValue 三方合并 JSON 值，*OK 表示该键是否存在；对象按键递归，其他值整体比较
+func mergeJSONValue(base, ours, theirs interface{}, baseOK, oursOK, theirsOK bool, path string) (interface{}, bool, error) {
+	sameOurs := oursOK == baseOK && reflect.DeepEqual(ours, base)
+	sameTheirs := theirsOK == baseOK && reflect.DeepEqual(theirs, base)
+	switch {
+	case sameOurs:
+		return theirs, theirsOK, nil
+	case sameTheirs || (oursOK == theirsOK && reflect.DeepEqual(ours, theirs)):
+		return ours, oursOK, nil
+	}
+
+	oursMap, oursIsMap := ours.(map[string]interface{})
+	theirsMap, theirsIsMap := theirs.(map[string]interface{})
+	baseMap, baseIsMap := base.(map[string]interface{})
+	if !oursIsMap || !theirsIsMap || (baseOK && !baseIsMap) {
+		if path == "" {
+			path = "/"
+		}
+		return nil, false, errors.New(path)
+	}
+
+	keys := make(map[string]struct{})
+	for _, side := range []map[string]interface{}{baseMap, oursMap, theirsMap} {
+		for k := range side {
+			keys[k] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	result := make(map[string]interface{}, len(keys))
+	var conflicts []string
+	for _, k := range sorted {
+		b, bOK := baseMap[k]
+		o, oOK := oursMap[k]
+		t, tOK := theirsMap[k]
+		v, ok, err := mergeJSONValue(b, o, t, bOK, oOK, tOK, strings.TrimPrefix(path+"."+k, "."))
+		if err != nil {
+			conflicts = append(conflicts, err.Error())
+			continue
+		}
+		if ok {
+			result[k] = v
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, false, errors.New(strings.Join(conflicts, ", "))
+	}
+	return result, true, nil
+}
+
+// normalizeConfigJSON 经 MultiAppConfig 往返转换，使三方内容的字段与格式一致
+func normalizeConfigJSON(data []byte) (interface{}, error) {
+	cfg := &MultiAppConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return toGenericJSON(cfg)
+}
+
+// toGenericJSON 将结构体转换为 map[string]interface{} 形式
+func toGenericJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 JSON 失败: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
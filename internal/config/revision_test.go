@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSaveMergesChangesFromOtherProcess(t *testing.T) {
+	tmpDir := t.TempDir()
+	first, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := first.AddProviderForApp("claude", "base", "", "sk-0", "https://base.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// 两个进程同时基于同一份 config.json 修改
+	a, _ := NewManagerWithDir(tmpDir)
+	b, _ := NewManagerWithDir(tmpDir)
+	if err := a.AddProviderForApp("claude", "from-a", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddProviderForApp("codex", "from-b", "", "sk-b", "https://b.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("不同字段的修改应自动合并: %v", err)
+	}
+
+	check, _ := NewManagerWithDir(tmpDir)
+	if _, err := check.GetProviderForApp("claude", "from-a"); err != nil {
+		t.Errorf("a 的修改被覆盖: %v", err)
+	}
+	if _, err := check.GetProviderForApp("codex", "from-b"); err != nil {
+		t.Errorf("b 的修改丢失: %v", err)
+	}
+	if _, err := b.GetProviderForApp("claude", "from-a"); err != nil {
+		t.Errorf("合并后内存中的配置应包含 a 的修改: %v", err)
+	}
+}
+
+func TestSaveReportsConflict(t *testing.T) {
+	tmpDir := t.TempDir()
+	first, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := first.AddProviderForApp("claude", "shared", "", "sk-0", "https://base.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := NewManagerWithDir(tmpDir)
+	b, _ := NewManagerWithDir(tmpDir)
+	if err := a.UpdateProviderForApp("claude", "shared", "shared", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	err = b.UpdateProviderForApp("claude", "shared", "shared", "", "sk-b", "https://b.example.com", "custom", "", "", "", "")
+	if !errors.Is(err, ErrConfigConflict) {
+		t.Fatalf("同一字段被改成不同值应报冲突, got %v", err)
+	}
+
+	check, _ := NewManagerWithDir(tmpDir)
+	p, _ := check.GetProviderForApp("claude", "shared")
+	if got := ExtractBaseURLFromProvider(p); got != "https://a.example.com" {
+		t.Errorf("冲突时不应覆盖文件, base url = %s", got)
+	}
+}
+
+func TestMergeJSONValue(t *testing.T) {
+	base := map[string]interface{}{"a": 1.0, "b": map[string]interface{}{"x": "1", "y": "2"}}
+	ours := map[string]interface{}{"a": 2.0, "b": map[string]interface{}{"x": "1", "y": "2"}}
+	theirs := map[string]interface{}{"a": 1.0, "b": map[string]interface{}{"y": "3"}, "c": true}
+
+	merged, _, err := mergeJSONValue(base, ours, theirs, true, true, true, "")
+	if err != nil {
+		t.Fatalf("mergeJSONValue() error = %v", err)
+	}
+	got := merged.(map[string]interface{})
+	b := got["b"].(map[string]interface{})
+	if got["a"] != 2.0 || got["c"] != true || b["y"] != "3" {
+		t.Errorf("merged = %v", got)
+	}
+	if _, ok := b["x"]; ok {
+		t.Errorf("对方删除的键应被删除: %v", b)
+	}
+
+	ours["b"] = map[string]interface{}{"x": "9", "y": "2"}
+	theirs["b"] = map[string]interface{}{"y": "3"}
+	if _, _, err := mergeJSONValue(base, ours, theirs, true, true, true, ""); err == nil || err.Error() != "b.x" {
+		t.Errorf("应报告冲突路径 b.x, got %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/secrets"
+)
+
+// PassphraseFunc 获取加密口令的回调（CLI 从环境变量或终端读取）
+type PassphraseFunc func(prompt string) (string, error)
+
+// defaultPassphraseFunc 新建 Manager 时使用的默认口令回调
+var defaultPassphraseFunc PassphraseFunc
+
+// SetDefaultPassphraseFunc 设置所有新建 Manager 的默认口令回调
+func SetDefaultPassphraseFunc(f PassphraseFunc) {
+	defaultPassphraseFunc = f
+}
+
+// secretFieldPaths 需要加密保存的字段（settingsConfig 下的 section.key）
+var secretFieldPaths = [][2]string{
+	{"env", "ANTHROPIC_AUTH_TOKEN"},
+	{"env", "ANTHROPIC_API_KEY"},
+	{"auth", "OPENAI_API_KEY"},
+	{"env", "GEMINI_API_KEY"},
+	{"env", "GOOGLE_GEMINI_API_KEY"},
+}
+
+// SetPassphraseFunc 设置当前 Manager 的口令回调
+func (m *Manager) SetPassphraseFunc(f PassphraseFunc) {
+	m.passphraseFunc = f
+}
+
+// SecretsEnabled 是否启用了 Token 加密
+func (m *Manager) SecretsEnabled() bool {
+	return m.config.Secrets != nil
+}
+
+// SecretsUnlocked 是否已经解锁（已派生密钥）
+func (m *Manager) SecretsUnlocked() bool {
+	return m.secretKey != nil
+}
+
+// UnlockSecrets 通过口令回调获取口令并派生密钥；未启用加密或已解锁时直接返回
+func (m *Manager) UnlockSecrets() error {
+	if !m.SecretsEnabled() || m.secretKey != nil {
+		return nil
+	}
+	if m.passphraseFunc == nil {
+		return fmt.Errorf("配置已加密，但未提供口令")
+	}
+
+	passphrase, err := m.passphraseFunc("请输入加密口令: ")
+	if err != nil {
+		return fmt.Errorf("读取口令失败: %w", err)
+	}
+	return m.UnlockSecretsWithPassphrase(passphrase)
+}
+
+// UnlockSecretsWithPassphrase 使用指定口令解锁
+func (m *Manager) UnlockSecretsWithPassphrase(passphrase string) error {
+	if !m.SecretsEnabled() {
+		return nil
+	}
+	key, err := secrets.DeriveKey(passphrase, m.config.Secrets)
+	if err != nil {
+		return err
+	}
+	if err := key.Verify(m.config.Secrets); err != nil {
+		return err
+	}
+	m.secretKey = key
+	return nil
+}
+
+// EnableSecrets 启用加密：生成新的密钥参数并加密所有 Token
+func (m *Manager) EnableSecrets(passphrase string) error {
+	if m.SecretsEnabled() {
+		return fmt.Errorf("Token 加密已启用")
+	}
+	return m.resealSecrets(passphrase)
+}
+
+// DisableSecrets 禁用加密：解密所有 Token 并以明文保存（需要先解锁）
+func (m *Manager) DisableSecrets() error {
+	if !m.SecretsEnabled() {
+		return fmt.Errorf("Token 加密未启用")
+	}
+	if err := m.UnlockSecrets(); err != nil {
+		return err
+	}
+	if err := m.decryptAllSecrets(); err != nil {
+		return err
+	}
+	m.config.Secrets = nil
+	m.secretKey = nil
+	return m.Save()
+}
+
+// RotateSecrets 使用新口令和新盐值重新加密所有 Token（需要先解锁）
+func (m *Manager) RotateSecrets(newPassphrase string) error {
+	if !m.SecretsEnabled() {
+		return fmt.Errorf("Token 加密未启用")
+	}
+	if err := m.UnlockSecrets(); err != nil {
+		return err
+	}
+	if err := m.decryptAllSecrets(); err != nil {
+		return err
+	}
+	return m.resealSecrets(newPassphrase)
+}
+
+// resealSecrets 生成新参数、派生密钥并加密所有明文 Token 后保存
+func (m *Manager) resealSecrets(passphrase string) error {
+	params, err := secrets.NewParams()
+	if err != nil {
+		return err
+	}
+	key, err := secrets.DeriveKey(passphrase, params)
+	if err != nil {
+		return err
+	}
+	if err := key.Seal(params); err != nil {
+		return err
+	}
+
+	m.config.Secrets = params
+	m.secretKey = key
+	if err := m.Save(); err != nil {
+		return err
+	}
+	// 历史快照中保存的是明文或旧密钥加密的 Token，一并清除
+	return m.historyStore().Clear()
+}
+
+// SecretStats 统计已加密、明文和外部引用的 Token 数量
+func (m *Manager) SecretStats() (encrypted, plaintext, references int) {
+	for _, app := range m.config.Apps {
+		for _, p := range app.Providers {
+			forEachSecret(p.SettingsConfig, func(section map[string]interface{}, key, value string) {
+				switch {
+				case secrets.IsEncrypted(value):
+					encrypted++
+				case secrets.IsReference(value):
+					references++
+				default:
+					plaintext++
+				}
+			})
+		}
+	}
+	return
+}
+
+// forEachSecret 遍历 settingsConfig 中所有非空的敏感字段
+func forEachSecret(settingsConfig map[string]interface{}, fn func(section map[string]interface{}, key, value string)) {
+	if settingsConfig == nil {
+		return
+	}
+	for _, path := range secretFieldPaths {
+		section, ok := settingsConfig[path[0]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, ok := section[path[1]].(string); ok && value != "" {
+			fn(section, path[1], value)
+		}
+	}
+}
+
+// hasPlaintextSecrets 是否存在尚未加密的 Token
+func (m *Manager) hasPlaintextSecrets() bool {
+	_, plaintext, _ := m.SecretStats()
+	return plaintext > 0
+}
+
+// encryptAllSecrets 加密所有明文 Token（原地修改），外部引用不含机密，保持原样
+func (m *Manager) encryptAllSecrets() error {
+	var firstErr error
+	for _, app := range m.config.Apps {
+		for _, p := range app.Providers {
+			forEachSecret(p.SettingsConfig, func(section map[string]interface{}, key, value string) {
+				if firstErr != nil || secrets.IsEncrypted(value) || secrets.IsReference(value) {
+					return
+				}
+				encrypted, err := m.secretKey.Encrypt(value)
+				if err != nil {
+					firstErr = err
+					return
+				}
+				section[key] = encrypted
+			})
+		}
+	}
+	return firstErr
+}
+
+// decryptAllSecrets 解密所有 Token（原地修改）
+func (m *Manager) decryptAllSecrets() error {
+	var firstErr error
+	for _, app := range m.config.Apps {
+		for _, p := range app.Providers {
+			forEachSecret(p.SettingsConfig, func(section map[string]interface{}, key, value string) {
+				if firstErr != nil || !secrets.IsEncrypted(value) {
+					return
+				}
+				plain, err := m.secretKey.Decrypt(value)
+				if err != nil {
+					firstErr = fmt.Errorf("解密 %s 失败: %w", key, err)
+					return
+				}
+				section[key] = plain
+			})
+		}
+	}
+	return firstErr
+}
+
+// prepareSecretsForSave 保存前加密新增的明文 Token；仅在确有明文时才需要解锁
+func (m *Manager) prepareSecretsForSave() error {
+	if !m.SecretsEnabled() || !m.hasPlaintextSecrets() {
+		return nil
+	}
+	if err := m.UnlockSecrets(); err != nil {
+		return fmt.Errorf("加密 Token 失败: %w", err)
+	}
+	return m.encryptAllSecrets()
+}
+
+// resolveProviderSecrets 返回敏感字段已解密、外部引用已解析的 provider 副本，仅用于写入 live 配置
+// provider 不含加密值和引用时直接返回原对象
+func (m *Manager) resolveProviderSecrets(provider *Provider) (*Provider, error) {
+	if provider == nil {
+		return nil, nil
+	}
+
+	needsDecrypt, needsResolve := false, false
+	forEachSecret(provider.SettingsConfig, func(section map[string]interface{}, key, value string) {
+		if secrets.IsEncrypted(value) {
+			needsDecrypt = true
+		} else if secrets.IsReference(value) {
+			needsResolve = true
+		}
+	})
+	if !needsDecrypt && !needsResolve {
+		return provider, nil
+	}
+
+	if needsDecrypt {
+		if err := m.UnlockSecrets(); err != nil {
+			return nil, err
+		}
+		if m.secretKey == nil {
+			return nil, fmt.Errorf("配置包含加密 Token，但未启用加密参数")
+		}
+	}
+
+	resolved := *provider
+	settingsCopy, err := deepCopySettings(provider.SettingsConfig)
+	if err != nil {
+		return nil, err
+	}
+	resolved.SettingsConfig = settingsCopy
+
+	var firstErr error
+	forEachSecret(resolved.SettingsConfig, func(section map[string]interface{}, key, value string) {
+		if firstErr != nil {
+			return
+		}
+		if secrets.IsEncrypted(value) {
+			plain, err := m.secretKey.Decrypt(value)
+			if err != nil {
+				firstErr = fmt.Errorf("解密 %s 失败: %w", key, err)
+				return
+			}
+			value = plain
+		}
+		plain, err := secrets.Resolve(context.Background(), value)
+		if err != nil {
+			firstErr = fmt.Errorf("%s: %w", key, err)
+			return
+		}
+		section[key] = plain
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return &resolved, nil
+}
+
+// secretMatches 判断保存的值（可能已加密）与 live 中的明文是否一致，用于回填时避免重复加密
+// 外部引用始终视为一致，避免用解析后的明文覆盖引用
+func (m *Manager) secretMatches(stored, live string) bool {
+	if stored == live || secrets.IsReference(stored) {
+		return true
+	}
+	if !secrets.IsEncrypted(stored) {
+		return false
+	}
+	if err := m.UnlockSecrets(); err != nil || m.secretKey == nil {
+		// 无法解锁时保持原值，避免用 live 明文覆盖加密值
+		return true
+	}
+	plain, err := m.secretKey.Decrypt(stored)
+	if err != nil {
+		return true
+	}
+	return plain == live
+}
+
+// DecryptConfigSecrets 使用口令解密配置（如导入文件）中的所有 Token，并移除加密参数
+func DecryptConfigSecrets(cfg *MultiAppConfig, passphrase string) error {
+	if cfg == nil || cfg.Secrets == nil {
+		return nil
+	}
+	key, err := secrets.DeriveKey(passphrase, cfg.Secrets)
+	if err != nil {
+		return err
+	}
+	if err := key.Verify(cfg.Secrets); err != nil {
+		return err
+	}
+
+	tmp := &Manager{config: cfg, secretKey: key}
+	if err := tmp.decryptAllSecrets(); err != nil {
+		return err
+	}
+	cfg.Secrets = nil
+	return nil
+}
+
+// deepCopySettings 深拷贝 settingsConfig，避免修改共享的 map
+func deepCopySettings(settingsConfig map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(settingsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("复制配置失败: %w", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("复制配置失败: %w", err)
+	}
+	return result, nil
+}
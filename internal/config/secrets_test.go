@@ -0,0 +1,166 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretsEnableSwitchDisable(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	manager.SetPassphraseFunc(func(string) (string, error) { return "pass", nil })
+
+	if err := manager.AddProviderForApp("claude", "A", "", "sk-plain-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.EnableSecrets("pass"); err != nil {
+		t.Fatalf("EnableSecrets() error = %v", err)
+	}
+
+	// 启用后新增的 Token 也应加密保存
+	if err := manager.AddProviderForApp("claude", "B", "", "sk-plain-b", "https://b.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "config.json"))
+	if strings.Contains(string(data), "sk-plain") {
+		t.Fatalf("config.json 中不应包含明文 Token:\n%s", data)
+	}
+
+	// 使用新的 Manager（模拟新进程）切换，live 文件应写入明文
+	reloaded, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	reloaded.SetPassphraseFunc(func(string) (string, error) { return "pass", nil })
+	if err := reloaded.SwitchProviderForApp("claude", "B"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	var settings ClaudeSettings
+	live, _ := os.ReadFile(filepath.Join(tmpDir, ".claude", "settings.json"))
+	json.Unmarshal(live, &settings)
+	if settings.Env.Get("ANTHROPIC_AUTH_TOKEN") != "sk-plain-b" {
+		t.Errorf("live token = %q, want sk-plain-b", settings.Env.Get("ANTHROPIC_AUTH_TOKEN"))
+	}
+
+	// 回填未修改的 Token 时不应产生明文
+	data, _ = os.ReadFile(filepath.Join(tmpDir, "config.json"))
+	if strings.Contains(string(data), "sk-plain") {
+		t.Fatalf("切换后 config.json 中不应包含明文 Token")
+	}
+
+	// 显示时只显示掩码
+	b, _ := reloaded.GetProviderForApp("claude", "B")
+	if masked := MaskToken(ExtractTokenFromProvider(b)); strings.Contains(masked, "plain") {
+		t.Errorf("MaskToken() = %q", masked)
+	}
+
+	if err := reloaded.DisableSecrets(); err != nil {
+		t.Fatalf("DisableSecrets() error = %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(tmpDir, "config.json"))
+	if !strings.Contains(string(data), "sk-plain-a") || strings.Contains(string(data), `"secrets"`) {
+		t.Errorf("禁用后应恢复明文并移除加密参数:\n%s", data)
+	}
+}
+
+func TestSecretsWrongPassphrase(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, _ := NewManagerWithDir(tmpDir)
+	if err := manager.AddProviderForApp("claude", "A", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "B", "", "sk-b", "https://b.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.EnableSecrets("right"); err != nil {
+		t.Fatalf("EnableSecrets() error = %v", err)
+	}
+
+	reloaded, _ := NewManagerWithDir(tmpDir)
+	reloaded.SetPassphraseFunc(func(string) (string, error) { return "wrong", nil })
+	if err := reloaded.SwitchProviderForApp("claude", "B"); err == nil {
+		t.Error("口令错误时切换应该失败")
+	}
+}
+
+func TestSecretsRotate(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, _ := NewManagerWithDir(tmpDir)
+	if err := manager.AddProviderForApp("codex", "C", "", "sk-codex", "https://c.example.com/v1", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.EnableSecrets("old"); err != nil {
+		t.Fatalf("EnableSecrets() error = %v", err)
+	}
+	if err := manager.RotateSecrets("new"); err != nil {
+		t.Fatalf("RotateSecrets() error = %v", err)
+	}
+
+	reloaded, _ := NewManagerWithDir(tmpDir)
+	if err := reloaded.UnlockSecretsWithPassphrase("old"); err == nil {
+		t.Error("旧口令不应再能解锁")
+	}
+	if err := reloaded.UnlockSecretsWithPassphrase("new"); err != nil {
+		t.Fatalf("新口令解锁失败: %v", err)
+	}
+	if err := reloaded.SwitchProviderForApp("codex", "C"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+	auth, _ := os.ReadFile(filepath.Join(tmpDir, ".codex", "auth.json"))
+	if !strings.Contains(string(auth), "sk-codex") {
+		t.Errorf("auth.json = %s", auth)
+	}
+}
+
+func TestSecretReferenceResolvedOnSwitch(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	t.Setenv("CCS_TEST_RELAY_KEY", "sk-from-env")
+
+	if err := manager.AddProviderForApp("claude", "Ref", "", "env:CCS_TEST_RELAY_KEY", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "Other", "", "sk-other", "https://b.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.SwitchProviderForApp("claude", "Ref"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	var settings ClaudeSettings
+	live, _ := os.ReadFile(filepath.Join(tmpDir, ".claude", "settings.json"))
+	json.Unmarshal(live, &settings)
+	if settings.Env.Get("ANTHROPIC_AUTH_TOKEN") != "sk-from-env" {
+		t.Errorf("live token = %q, want sk-from-env", settings.Env.Get("ANTHROPIC_AUTH_TOKEN"))
+	}
+
+	// 切走时回填不应用解析后的值覆盖引用
+	if err := manager.SwitchProviderForApp("claude", "Other"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+	ref, _ := manager.GetProviderForApp("claude", "Ref")
+	token := ExtractTokenFromProvider(ref)
+	if token != "env:CCS_TEST_RELAY_KEY" {
+		t.Errorf("stored token = %q, 应保留引用", token)
+	}
+	if masked := MaskToken(token); masked != token {
+		t.Errorf("MaskToken() = %q, 应显示引用本身", masked)
+	}
+
+	// 引用无法解析时切换失败
+	os.Unsetenv("CCS_TEST_RELAY_KEY")
+	if err := manager.SwitchProviderForApp("claude", "Ref"); err == nil {
+		t.Error("引用无法解析时应返回错误")
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import "fmt"
+
+// EnvVar 单个环境变量
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// shellEnvNames 各应用通过环境变量生效时使用的变量（按输出顺序）
+var shellEnvNames = map[string][]string{
+	"claude": {
+		"ANTHROPIC_AUTH_TOKEN",
+		"ANTHROPIC_BASE_URL",
+		"ANTHROPIC_MODEL",
+		"ANTHROPIC_DEFAULT_HAIKU_MODEL",
+		"ANTHROPIC_DEFAULT_SONNET_MODEL",
+		"ANTHROPIC_DEFAULT_OPUS_MODEL",
+	},
+	"codex": {
+		"OPENAI_API_KEY",
+		"OPENAI_BASE_URL",
+	},
+	"gemini": {
+		"GEMINI_API_KEY",
+		"GOOGLE_GEMINI_BASE_URL",
+		"GEMINI_MODEL",
+	},
+}
+
+// ShellEnvNames 返回应用对应的环境变量名（用于 unset）
+func ShellEnvNames(appName string) ([]string, error) {
+	names, ok := shellEnvNames[appName]
+	if !ok {
+		return nil, fmt.Errorf("不支持的应用: %s", appName)
+	}
+	return names, nil
+}
+
+// ProviderShellEnv 返回让指定配置在当前终端生效所需的环境变量
+// Token 会被解密/解析为实际值，空值不输出
+func (m *Manager) ProviderShellEnv(appName, name string) ([]EnvVar, error) {
+	names, err := ShellEnvNames(appName)
+	if err != nil {
+		return nil, err
+	}
+	_, provider, err := m.findProviderByName(appName, name)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := m.resolveProviderSecrets(&provider)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Token 失败: %w", err)
+	}
+
+	values := make(map[string]string)
+	switch appName {
+	case "claude":
+		values["ANTHROPIC_AUTH_TOKEN"] = ExtractTokenFromProvider(resolved)
+		values["ANTHROPIC_BASE_URL"] = ExtractBaseURLFromProvider(resolved)
+		values["ANTHROPIC_MODEL"] = ExtractAnthropicModelFromProvider(resolved)
+		values["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = ExtractDefaultHaikuModelFromProvider(resolved)
+		values["ANTHROPIC_DEFAULT_SONNET_MODEL"] = ExtractDefaultSonnetModelFromProvider(resolved)
+		values["ANTHROPIC_DEFAULT_OPUS_MODEL"] = ExtractDefaultOpusModelFromProvider(resolved)
+	case "codex":
+		values["OPENAI_API_KEY"] = ExtractTokenFromProvider(resolved)
+		values["OPENAI_BASE_URL"] = ExtractBaseURLFromProvider(resolved)
+	case "gemini":
+		baseURL, apiKey, model, _ := ExtractGeminiConfigFromProvider(resolved)
+		values["GEMINI_API_KEY"] = apiKey
+		values["GOOGLE_GEMINI_BASE_URL"] = baseURL
+		values["GEMINI_MODEL"] = model
+	}
+
+	vars := make([]EnvVar, 0, len(names))
+	for _, n := range names {
+		if v := values[n]; v != "" {
+			vars = append(vars, EnvVar{Name: n, Value: v})
+		}
+	}
+	return vars, nil
+}
+
+// ProviderExecEnv 返回以指定配置运行子进程时注入的环境变量
+// Claude 注入完整的 env 映射，Gemini 注入 .env 中的全部变量，Codex 注入 API Key 和 Base URL
+func (m *Manager) ProviderExecEnv(appName, name string) (map[string]string, error) {
+	if appName == "codex" {
+		vars, err := m.ProviderShellEnv(appName, name)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]string, len(vars))
+		for _, v := range vars {
+			result[v.Name] = v.Value
+		}
+		return result, nil
+	}
+
+	if _, err := ShellEnvNames(appName); err != nil {
+		return nil, err
+	}
+	_, provider, err := m.findProviderByName(appName, name)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := m.resolveProviderSecrets(&provider)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Token 失败: %w", err)
+	}
+
+	if appName == "gemini" {
+		return NormalizeGeminiEnv(resolved), nil
+	}
+
+	result := make(map[string]string)
+	if envMap, ok := resolved.SettingsConfig["env"].(map[string]interface{}); ok {
+		for key, val := range envMap {
+			if s, ok := val.(string); ok && s != "" {
+				result[key] = s
+			}
+		}
+	}
+	// settingsConfig.model 在 live 配置中对应 ANTHROPIC_MODEL
+	if _, ok := result["ANTHROPIC_MODEL"]; !ok {
+		if model := ExtractAnthropicModelFromProvider(resolved); model != "" {
+			result["ANTHROPIC_MODEL"] = model
+		}
+	}
+	return result, nil
+}
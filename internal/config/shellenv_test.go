@@ -0,0 +1,80 @@
+package config
+
+import "testing"
+
+func TestProviderShellEnv(t *testing.T) {
+	manager, err := NewManagerWithDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	t.Setenv("CCS_TEST_SHELL_KEY", "sk-from-env")
+
+	if err := manager.AddProviderForApp("claude", "work", "", "env:CCS_TEST_SHELL_KEY", "https://api.example.com", "custom", "claude-sonnet", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddGeminiProvider("gm", "https://gemini.example.com", "gm-key", "gemini-pro", GeminiAuthAPIKey); err != nil {
+		t.Fatalf("添加 Gemini 配置失败: %v", err)
+	}
+
+	vars, err := manager.ProviderShellEnv("claude", "work")
+	if err != nil {
+		t.Fatalf("ProviderShellEnv() error = %v", err)
+	}
+	got := make(map[string]string)
+	for _, v := range vars {
+		got[v.Name] = v.Value
+	}
+	if got["ANTHROPIC_AUTH_TOKEN"] != "sk-from-env" {
+		t.Errorf("ANTHROPIC_AUTH_TOKEN = %q, 引用应被解析", got["ANTHROPIC_AUTH_TOKEN"])
+	}
+	if got["ANTHROPIC_BASE_URL"] != "https://api.example.com" || got["ANTHROPIC_MODEL"] != "claude-sonnet" {
+		t.Errorf("vars = %v", got)
+	}
+	if _, ok := got["ANTHROPIC_DEFAULT_OPUS_MODEL"]; ok {
+		t.Error("空值不应输出")
+	}
+
+	vars, err = manager.ProviderShellEnv("gemini", "gm")
+	if err != nil {
+		t.Fatalf("ProviderShellEnv(gemini) error = %v", err)
+	}
+	if len(vars) != 3 || vars[0].Name != "GEMINI_API_KEY" || vars[0].Value != "gm-key" {
+		t.Errorf("gemini vars = %v", vars)
+	}
+
+	if _, err := manager.ProviderShellEnv("claude", "missing"); err == nil {
+		t.Error("不存在的配置应返回错误")
+	}
+}
+
+func TestProviderExecEnv(t *testing.T) {
+	manager, err := NewManagerWithDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "work", "", "sk-work", "https://api.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	currentBefore := manager.config.Apps["claude"].Current
+
+	// 额外的 env 变量也应注入
+	app := manager.config.Apps["claude"]
+	for id, p := range app.Providers {
+		p.SettingsConfig["env"].(map[string]interface{})["DISABLE_TELEMETRY"] = "1"
+		app.Providers[id] = p
+	}
+
+	env, err := manager.ProviderExecEnv("claude", "work")
+	if err != nil {
+		t.Fatalf("ProviderExecEnv() error = %v", err)
+	}
+	if env["ANTHROPIC_AUTH_TOKEN"] != "sk-work" || env["DISABLE_TELEMETRY"] != "1" {
+		t.Errorf("env = %v", env)
+	}
+
+	// 不修改当前配置
+	if manager.config.Apps["claude"].Current != currentBefore {
+		t.Error("exec 不应修改 current")
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	toml "github.com/pelletier/go-toml/v2"
 
+	"github.com/YangQing-Lin/cc-switch-cli/internal/tomledit"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/writeset"
 )
 
 func (m *Manager) SwitchProvider(name string) error {
@@ -16,6 +19,8 @@ func (m *Manager) SwitchProvider(name string) error {
 }
 
 func (m *Manager) SwitchProviderForApp(appName, name string) error {
+	defer m.BeginOperation(fmt.Sprintf("切换 %s 到 '%s'", appName, name))()
+
 	app, exists := m.config.Apps[appName]
 	if !exists {
 		return fmt.Errorf("应用 '%s' 不存在", appName)
@@ -36,37 +41,134 @@ func (m *Manager) SwitchProviderForApp(appName, name string) error {
 		return fmt.Errorf("配置 '%s' 不存在", name)
 	}
 
-	if err := m.writeProviderConfig(appName, targetProvider); err != nil {
-		return fmt.Errorf("写入配置失败: %w", err)
+	// 回填：切换前将 live 文件中的修改保存回当前供应商（重新应用当前供应商时跳过，以便用其覆盖 live）
+	if app.Current != "" && app.Current != targetID {
+		if current, ok := app.Providers[app.Current]; ok {
+			changed, err := m.backfillProviderConfig(appName, &current)
+			if err != nil {
+				return fmt.Errorf("回填当前配置失败: %w", err)
+			}
+			if changed {
+				app.Providers[app.Current] = current
+				m.config.Apps[appName] = app
+			}
+		}
 	}
 
+	previous := app.Current
 	app.Current = targetID
 	m.config.Apps[appName] = app
-	return m.Save()
+
+	// live 文件和 config.json 一起提交，任一失败则全部回滚，保证 current 与 live 一致
+	if err := m.commitProviderConfig(appName, targetProvider); err != nil {
+		app.Current = previous
+		m.config.Apps[appName] = app
+		return fmt.Errorf("写入配置失败: %w", err)
+	}
+	return nil
+}
+
+// newWriteSet 创建写入集合，日志文件位于配置目录
+func (m *Manager) newWriteSet() *writeset.WriteSet {
+	return writeset.New(m.journalPath())
+}
+
+// journalPath 切换日志路径，存在即表示上次切换未完成
+func (m *Manager) journalPath() string {
+	return filepath.Join(filepath.Dir(m.configPath), "switch.journal")
 }
 
-func (m *Manager) writeProviderConfig(appName string, provider *Provider) error {
-	switch appName {
-	case "claude":
-		return m.writeClaudeConfig(provider)
-	case "codex":
-		return m.writeCodexConfig(provider)
-	case "gemini":
-		return m.writeGeminiConfig(provider)
-	default:
-		return fmt.Errorf("不支持的应用: %s", appName)
+// recoverInterruptedSwitch 恢复上次被中断的切换，在加载配置前调用
+func (m *Manager) recoverInterruptedSwitch() error {
+	recovered, err := writeset.Recover(m.journalPath())
+	if err != nil {
+		return fmt.Errorf("恢复未完成的切换失败: %w", err)
+	}
+	if recovered {
+		fmt.Fprintln(os.Stderr, "⚠ 检测到上次切换未完成，已恢复到切换前的状态")
+	}
+	return nil
+}
+
+// commitProviderConfig 将供应商的 live 配置与 config.json 作为一个整体写入
+// 整个提交过程持有 config.json 文件锁
+func (m *Manager) commitProviderConfig(appName string, provider *Provider) error {
+	return m.withConfigLock(func() error {
+		ws := m.newWriteSet()
+		if err := m.stageProviderConfig(ws, appName, provider); err != nil {
+			return err
+		}
+		data, err := m.encodeConfigForSave()
+		if err != nil {
+			return err
+		}
+		ws.Write(m.configPath, data, 0600)
+		if err := ws.Commit(); err != nil {
+			return err
+		}
+		m.baseData = data
+		return nil
+	})
+}
+
+func (m *Manager) stageProviderConfig(ws *writeset.WriteSet, appName string, provider *Provider) error {
+	app, err := m.App(appName)
+	if err != nil {
+		return err
+	}
+	return app.Stage(m, ws, provider)
+}
+
+// SwitchClaudeProviderToScope 将 Claude 配置写入指定范围的设置文件
+// 项目范围只写入当前目录的 .claude 设置文件，不修改全局 current，也不回填
+func (m *Manager) SwitchClaudeProviderToScope(name, scope string) error {
+	if scope == "" || scope == ClaudeScopeGlobal {
+		return m.SwitchProviderForApp("claude", name)
+	}
+
+	settingsPath, err := m.GetClaudeSettingsPathForScope(scope)
+	if err != nil {
+		return err
+	}
+	defer m.BeginOperation(fmt.Sprintf("切换 claude (%s 范围) 到 '%s'", scope, name), settingsPath)()
+
+	_, provider, err := m.findProviderByName("claude", name)
+	if err != nil {
+		return err
+	}
+	ws := m.newWriteSet()
+	if _, err := m.stageClaudeSettingsFile(ws, &provider, settingsPath, m.claudeProviderEnvKeys()); err != nil {
+		return fmt.Errorf("写入配置失败: %w", err)
+	}
+	if err := ws.Commit(); err != nil {
+		return fmt.Errorf("写入配置失败: %w", err)
 	}
+	return nil
 }
 
-func (m *Manager) writeClaudeConfig(provider *Provider) error {
+// stageClaudeConfig 写入全局设置文件，并记录供应商拥有的变量（随 config.json 一起提交）
+func (m *Manager) stageClaudeConfig(ws *writeset.WriteSet, provider *Provider) error {
 	settingsPath, err := m.GetClaudeSettingsPathWithDir()
 	if err != nil {
 		return fmt.Errorf("获取 Claude 设置文件路径失败: %w", err)
 	}
 
-	dir := filepath.Dir(settingsPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("创建目录失败: %w", err)
+	app := m.config.Apps["claude"]
+	owned, err := m.stageClaudeSettingsFile(ws, provider, settingsPath, app.LiveEnvKeys)
+	if err != nil {
+		return err
+	}
+	app.LiveEnvKeys = owned
+	m.config.Apps["claude"] = app
+	return nil
+}
+
+// stageClaudeSettingsFile 将供应商的 env 和 model 写入设置文件，保留文件中的其他字段
+// previousOwned 为上一个供应商写入的变量，会先被移除；返回本次写入的变量名
+func (m *Manager) stageClaudeSettingsFile(ws *writeset.WriteSet, provider *Provider, settingsPath string, previousOwned []string) ([]string, error) {
+	provider, err := m.resolveProviderSecrets(provider)
+	if err != nil {
+		return nil, fmt.Errorf("解密 Token 失败: %w", err)
 	}
 
 	settings := &ClaudeSettings{
@@ -83,54 +185,76 @@ func (m *Manager) writeClaudeConfig(provider *Provider) error {
 		}
 	}
 
-	if envMap, ok := provider.SettingsConfig["env"].(map[string]interface{}); ok {
+	if settings.Env == nil {
 		settings.Env = ClaudeEnv{}
-		if token, ok := envMap["ANTHROPIC_AUTH_TOKEN"].(string); ok {
-			settings.Env.AnthropicAuthToken = token
-		}
-		if baseURL, ok := envMap["ANTHROPIC_BASE_URL"].(string); ok {
-			settings.Env.AnthropicBaseURL = baseURL
-		}
-		if model, ok := envMap["ANTHROPIC_MODEL"].(string); ok {
-			settings.Env.AnthropicModel = model
-		}
-		if defaultHaikuModel, ok := envMap["ANTHROPIC_DEFAULT_HAIKU_MODEL"].(string); ok {
-			settings.Env.AnthropicDefaultHaikuModel = defaultHaikuModel
-		}
-		if model, ok := envMap["CLAUDE_CODE_MODEL"].(string); ok {
-			settings.Env.ClaudeCodeModel = model
-		}
-		if maxTokens, ok := envMap["CLAUDE_CODE_MAX_TOKENS"].(string); ok {
-			settings.Env.ClaudeCodeMaxTokens = maxTokens
-		}
-		if defaultOpusModel, ok := envMap["ANTHROPIC_DEFAULT_OPUS_MODEL"].(string); ok {
-			settings.Env.AnthropicDefaultOpusModel = defaultOpusModel
-		}
-		if defaultSonnetModel, ok := envMap["ANTHROPIC_DEFAULT_SONNET_MODEL"].(string); ok {
-			settings.Env.AnthropicDefaultSonnetModel = defaultSonnetModel
+	}
+
+	// 移除上一个供应商拥有的变量，用户自己添加的变量保留
+	for _, key := range claudeManagedEnvKeys {
+		delete(settings.Env, key)
+	}
+	for _, key := range previousOwned {
+		delete(settings.Env, key)
+	}
+
+	owned := make(map[string]bool)
+	if envMap, ok := provider.SettingsConfig["env"].(map[string]interface{}); ok {
+		for key, val := range envMap {
+			if val == nil || val == "" {
+				continue
+			}
+			settings.Env[key] = val
+			owned[key] = true
 		}
 	}
 
 	if model, ok := provider.SettingsConfig["model"].(string); ok {
 		settings.Model = model
-	} else if settings.Env.AnthropicModel != "" {
-		settings.Model = settings.Env.AnthropicModel
 	} else {
-		settings.Model = ""
+		settings.Model = settings.Env.Get("ANTHROPIC_MODEL")
 	}
 
-	if settings.Env.AnthropicModel == "" && settings.Model != "" {
-		settings.Env.AnthropicModel = settings.Model
+	if settings.Env.Get("ANTHROPIC_MODEL") == "" && settings.Model != "" {
+		settings.Env["ANTHROPIC_MODEL"] = settings.Model
+		owned["ANTHROPIC_MODEL"] = true
 	}
 
-	if err := utils.WriteJSONFile(settingsPath, settings, 0644); err != nil {
-		return fmt.Errorf("保存设置失败: %w", err)
+	if err := ws.WriteJSON(settingsPath, settings, 0644); err != nil {
+		return nil, fmt.Errorf("保存设置失败: %w", err)
 	}
 
-	return nil
+	ownedKeys := make([]string, 0, len(owned))
+	for key := range owned {
+		ownedKeys = append(ownedKeys, key)
+	}
+	sort.Strings(ownedKeys)
+	return ownedKeys, nil
+}
+
+// claudeProviderEnvKeys 返回所有 Claude 供应商 env 中出现过的变量名
+// 用于没有写入记录的项目级设置文件：这些变量都视为由供应商拥有
+func (m *Manager) claudeProviderEnvKeys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, p := range m.config.Apps["claude"].Providers {
+		envMap, _ := p.SettingsConfig["env"].(map[string]interface{})
+		for key := range envMap {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-func (m *Manager) writeCodexConfig(provider *Provider) error {
+func (m *Manager) stageCodexConfig(ws *writeset.WriteSet, provider *Provider) error {
+	provider, err := m.resolveProviderSecrets(provider)
+	if err != nil {
+		return fmt.Errorf("解密 Token 失败: %w", err)
+	}
+
 	authJsonPath, err := m.GetCodexAuthJsonPathWithDir()
 	if err != nil {
 		return fmt.Errorf("获取 Codex auth.json 路径失败: %w", err)
@@ -141,11 +265,6 @@ func (m *Manager) writeCodexConfig(provider *Provider) error {
 		return fmt.Errorf("获取 Codex config.toml 路径失败: %w", err)
 	}
 
-	dir := filepath.Dir(authJsonPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("创建目录失败: %w", err)
-	}
-
 	authData := &CodexAuthJson{}
 	if authMap, ok := provider.SettingsConfig["auth"].(map[string]interface{}); ok {
 		if apiKey, ok := authMap["OPENAI_API_KEY"].(string); ok {
@@ -158,20 +277,14 @@ func (m *Manager) writeCodexConfig(provider *Provider) error {
 		return fmt.Errorf("序列化 auth.json 失败: %w", err)
 	}
 
-	if err := utils.AtomicWriteFile(authJsonPath, authJsonData, 0644); err != nil {
-		return fmt.Errorf("写入 auth.json 失败: %w", err)
-	}
+	ws.Write(authJsonPath, authJsonData, 0644)
 
 	// 获取 CCS 配置字符串
 	configContent, _ := provider.SettingsConfig["config"].(string)
 
 	// 如果 CCS 配置为空，删除 config.toml
 	if configContent == "" {
-		if utils.FileExists(configPath) {
-			if err := os.Remove(configPath); err != nil {
-				fmt.Printf("警告: 删除空配置文件失败: %v\n", err)
-			}
-		}
+		ws.Remove(configPath)
 		return nil
 	}
 
@@ -179,61 +292,50 @@ func (m *Manager) writeCodexConfig(provider *Provider) error {
 	var ccsConfig map[string]interface{}
 	if err := toml.Unmarshal([]byte(configContent), &ccsConfig); err != nil {
 		// 解析失败，回退到完全覆盖
-		return utils.AtomicWriteFile(configPath, []byte(configContent), 0644)
+		ws.Write(configPath, []byte(configContent), 0644)
+		return nil
 	}
 
 	// 读取现有配置（如果存在）
-	existingConfig := make(map[string]interface{})
+	var existingData []byte
 	if utils.FileExists(configPath) {
-		data, err := os.ReadFile(configPath)
-		if err == nil {
-			toml.Unmarshal(data, &existingConfig) // 忽略解析错误
+		if data, err := os.ReadFile(configPath); err == nil {
+			existingData = data
 		}
 	}
 
-	// 合并配置
-	mergedConfig := mergeCodexConfig(existingConfig, ccsConfig)
-
-	// 序列化并写入
-	data, err := toml.Marshal(mergedConfig)
+	data, err := editCodexConfig(existingData, ccsConfig)
 	if err != nil {
-		return fmt.Errorf("序列化 config.toml 失败: %w", err)
+		return err
 	}
-
-	return utils.AtomicWriteFile(configPath, data, 0644)
+	ws.Write(configPath, data, 0644)
+	return nil
 }
 
-// mergeCodexConfig 合并 Codex 配置，CCS 管理的字段覆盖，其他字段保留
-func mergeCodexConfig(existing, ccs map[string]interface{}) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	// 1. 复制 existing 中所有字段到 result
-	for k, v := range existing {
-		result[k] = v
-	}
+// codexEdit 对 config.toml 的一处修改
+type codexEdit struct {
+	path  []string
+	value interface{}
+}
 
-	// 2. 用 CCS 配置覆盖管理的字段
-	ccsFields := []string{
-		"model_provider",
-		"model",
-		"model_reasoning_effort",
-		"disable_response_storage",
-	}
-	for _, field := range ccsFields {
+// codexManagedEdits 返回切换到 CCS 配置时需要对 config.toml 做的修改
+func codexManagedEdits(ccs map[string]interface{}) []codexEdit {
+	var edits []codexEdit
+	for _, field := range codexManagedTopKeys {
 		if val, ok := ccs[field]; ok {
-			result[field] = val
+			edits = append(edits, codexEdit{path: []string{field}, value: val})
 		}
 	}
 
-	// 3. 合并 model_providers 段
+	// CCS 的 provider 段覆盖同名段，同时过滤废弃字段
 	if ccsProviders, ok := ccs["model_providers"].(map[string]interface{}); ok {
-		existingProviders := make(map[string]interface{})
-		if ep, ok := result["model_providers"].(map[string]interface{}); ok {
-			existingProviders = ep
+		names := make([]string, 0, len(ccsProviders))
+		for name := range ccsProviders {
+			names = append(names, name)
 		}
-
-		// CCS 的 provider 段覆盖同名段，同时过滤废弃字段
-		for name, config := range ccsProviders {
+		sort.Strings(names)
+		for _, name := range names {
+			config := ccsProviders[name]
 			if providerConfig, ok := config.(map[string]interface{}); ok {
 				// 过滤掉 env_key（已废弃）
 				filteredConfig := make(map[string]interface{})
@@ -242,11 +344,57 @@ func mergeCodexConfig(existing, ccs map[string]interface{}) map[string]interface
 						filteredConfig[k] = v
 					}
 				}
-				existingProviders[name] = filteredConfig
-			} else {
-				existingProviders[name] = config
+				config = filteredConfig
 			}
+			edits = append(edits, codexEdit{path: []string{"model_providers", name}, value: config})
+		}
+	}
+	return edits
+}
+
+// editCodexConfig 在现有 config.toml 上原地修改 CCS 管理的字段，保留注释、顺序和其他内容
+// 现有文件无法解析或原地修改结果与预期不一致时，回退为整体序列化
+func editCodexConfig(existing []byte, ccs map[string]interface{}) ([]byte, error) {
+	existingConfig := make(map[string]interface{})
+	toml.Unmarshal(existing, &existingConfig) // 忽略解析错误
+	merged := mergeCodexConfig(existingConfig, ccs)
+
+	if doc, err := tomledit.Parse(existing); err == nil {
+		for _, edit := range codexManagedEdits(ccs) {
+			doc.Set(edit.path, edit.value)
+		}
+		if data := doc.Bytes(); tomledit.Matches(data, merged) {
+			return data, nil
+		}
+	}
+
+	data, err := toml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 config.toml 失败: %w", err)
+	}
+	return data, nil
+}
+
+// mergeCodexConfig 合并 Codex 配置，CCS 管理的字段覆盖，其他字段保留
+func mergeCodexConfig(existing, ccs map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	// 1. 复制 existing 中所有字段到 result
+	for k, v := range existing {
+		result[k] = v
+	}
+
+	// 2. 用 CCS 配置覆盖管理的字段和 model_providers 段
+	for _, edit := range codexManagedEdits(ccs) {
+		if len(edit.path) == 1 {
+			result[edit.path[0]] = edit.value
+			continue
+		}
+		existingProviders := make(map[string]interface{})
+		if ep, ok := result["model_providers"].(map[string]interface{}); ok {
+			existingProviders = ep
 		}
+		existingProviders[edit.path[1]] = edit.value
 		result["model_providers"] = existingProviders
 	}
 
@@ -0,0 +1,467 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSwitchBackfillsClaudeLiveSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+
+	if err := manager.AddProviderForApp("claude", "A", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "B", "", "sk-b", "https://b.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	// 模拟用户直接编辑 live settings.json
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	live := map[string]interface{}{
+		"env": map[string]interface{}{
+			"ANTHROPIC_AUTH_TOKEN":           "sk-a-edited",
+			"ANTHROPIC_BASE_URL":             "https://a.example.com",
+			"ANTHROPIC_MODEL":                "claude-opus-custom",
+			"ANTHROPIC_DEFAULT_SONNET_MODEL": "sonnet-override",
+		},
+		"model":       "claude-opus-custom",
+		"permissions": map[string]interface{}{"allow": []string{}, "deny": []string{}},
+	}
+	data, _ := json.Marshal(live)
+	if err := os.WriteFile(settingsPath, data, 0644); err != nil {
+		t.Fatalf("写入 live 文件失败: %v", err)
+	}
+
+	if err := manager.SwitchProviderForApp("claude", "B"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	// 重新加载，确认回填结果已持久化
+	reloaded, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	a, err := reloaded.GetProviderForApp("claude", "A")
+	if err != nil {
+		t.Fatalf("获取配置失败: %v", err)
+	}
+
+	if got := ExtractTokenFromProvider(a); got != "sk-a-edited" {
+		t.Errorf("token 未回填, got %q", got)
+	}
+	if got := ExtractAnthropicModelFromProvider(a); got != "claude-opus-custom" {
+		t.Errorf("model 未回填, got %q", got)
+	}
+	if got := ExtractDefaultSonnetModelFromProvider(a); got != "sonnet-override" {
+		t.Errorf("sonnet model 未回填, got %q", got)
+	}
+
+	// live 文件应为 B 的配置
+	var written ClaudeSettings
+	raw, _ := os.ReadFile(settingsPath)
+	if err := json.Unmarshal(raw, &written); err != nil {
+		t.Fatalf("解析 live 文件失败: %v", err)
+	}
+	if written.Env.Get("ANTHROPIC_AUTH_TOKEN") != "sk-b" {
+		t.Errorf("live token = %q, want sk-b", written.Env.Get("ANTHROPIC_AUTH_TOKEN"))
+	}
+}
+
+func TestSwitchToCurrentSkipsBackfill(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "A", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	os.WriteFile(settingsPath, []byte(`{"env":{"ANTHROPIC_AUTH_TOKEN":"sk-live"}}`), 0644)
+
+	// 重新应用当前配置用于覆盖 live，不应回填
+	if err := manager.SwitchProviderForApp("claude", "A"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+	a, _ := manager.GetProviderForApp("claude", "A")
+	if got := ExtractTokenFromProvider(a); got != "sk-a" {
+		t.Errorf("token = %q, want sk-a", got)
+	}
+}
+
+func TestSwitchBackfillsCodexLiveConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("codex", "Relay", "", "sk-relay", "https://relay.example.com/v1", "custom", "gpt-5-codex", "", "high", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("codex", "Other", "", "sk-other", "https://other.example.com/v1", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	configPath := filepath.Join(tmpDir, ".codex", "config.toml")
+	liveToml := `model_provider = "relay"
+model = "gpt-5"
+model_reasoning_effort = "low"
+disable_response_storage = true
+
+[model_providers.relay]
+name = "Relay"
+base_url = "https://relay-new.example.com/v1"
+wire_api = "responses"
+requires_openai_auth = true
+
+[mcp_servers.local]
+command = "npx"
+`
+	if err := os.WriteFile(configPath, []byte(liveToml), 0644); err != nil {
+		t.Fatalf("写入 live 文件失败: %v", err)
+	}
+	authPath := filepath.Join(tmpDir, ".codex", "auth.json")
+	os.WriteFile(authPath, []byte(`{"OPENAI_API_KEY":"sk-relay-rotated"}`), 0644)
+
+	if err := manager.SwitchProviderForApp("codex", "Other"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	relay, _ := manager.GetProviderForApp("codex", "Relay")
+	if got := ExtractTokenFromProvider(relay); got != "sk-relay-rotated" {
+		t.Errorf("api key 未回填, got %q", got)
+	}
+	if got := ExtractModelFromProvider(relay); got != "gpt-5" {
+		t.Errorf("model 未回填, got %q", got)
+	}
+	if got := ExtractCodexReasoningFromProvider(relay); got != "low" {
+		t.Errorf("reasoning 未回填, got %q", got)
+	}
+	if got := ExtractBaseURLFromProvider(relay); got != "https://relay-new.example.com/v1" {
+		t.Errorf("base_url 未回填, got %q", got)
+	}
+	configStr, _ := relay.SettingsConfig["config"].(string)
+	if strings.Contains(configStr, "mcp_servers") {
+		t.Errorf("非受管字段不应回填到 provider: %s", configStr)
+	}
+}
+
+func TestSwitchBackfillsGeminiEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddGeminiProvider("G1", "https://g1.example.com", "key-1", "gemini-2.5-pro", GeminiAuthAPIKey); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddGeminiProvider("G2", "https://g2.example.com", "key-2", "", GeminiAuthAPIKey); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	envPath := filepath.Join(tmpDir, ".gemini", ".env")
+	content := "GOOGLE_GEMINI_BASE_URL=https://g1.example.com\nGEMINI_API_KEY=key-1\nGEMINI_MODEL=gemini-2.5-flash\n"
+	if err := os.WriteFile(envPath, []byte(content), 0600); err != nil {
+		t.Fatalf("写入 .env 失败: %v", err)
+	}
+
+	if err := manager.SwitchProviderForApp("gemini", "G2"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	g1, _ := manager.GetProviderForApp("gemini", "G1")
+	_, _, model, _ := ExtractGeminiConfigFromProvider(g1)
+	if model != "gemini-2.5-flash" {
+		t.Errorf("model 未回填, got %q", model)
+	}
+}
+
+func TestSwitchClaudeProviderToLocalScope(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "A", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "B", "", "sk-b", "https://b.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	currentBefore := manager.config.Apps["claude"].Current
+
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+
+	// 项目本地设置中已有的 permissions 和 hooks 需要保留
+	localPath := filepath.Join(projectDir, ".claude", "settings.local.json")
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	existing := `{"permissions":{"allow":["Bash(go test:*)"],"deny":[]},"hooks":{"Stop":[]}}`
+	if err := os.WriteFile(localPath, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.SwitchClaudeProviderToScope("B", ClaudeScopeLocal); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatalf("读取项目设置失败: %v", err)
+	}
+	var settings ClaudeSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("解析项目设置失败: %v", err)
+	}
+	if settings.Env.Get("ANTHROPIC_AUTH_TOKEN") != "sk-b" || settings.Env.Get("ANTHROPIC_BASE_URL") != "https://b.example.com" {
+		t.Errorf("env = %+v", settings.Env)
+	}
+	if len(settings.Permissions.Allow) != 1 {
+		t.Errorf("permissions 未保留: %+v", settings.Permissions)
+	}
+	if _, ok := settings.Extra["hooks"]; !ok {
+		t.Error("hooks 未保留")
+	}
+
+	if manager.config.Apps["claude"].Current != currentBefore {
+		t.Error("项目范围切换不应修改 current")
+	}
+
+	if _, err := manager.GetClaudeSettingsPathForScope("unknown"); err == nil {
+		t.Error("未知范围应返回错误")
+	}
+}
+
+func TestSwitchRollsBackOnPartialWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddGeminiProvider("A", "https://a.example.com", "key-a", "", GeminiAuthAPIKey); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddGeminiProvider("B", "https://b.example.com", "key-b", "", GeminiAuthAPIKey); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	envPath := filepath.Join(tmpDir, ".gemini", ".env")
+	envBefore, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("读取 .env 失败: %v", err)
+	}
+	configBefore, _ := os.ReadFile(manager.GetConfigPath())
+
+	// settings.json 被非空目录占用，.env 写入成功后 settings.json 写入必然失败
+	settingsPath := filepath.Join(tmpDir, ".gemini", "settings.json")
+	os.Remove(settingsPath)
+	if err := os.MkdirAll(filepath.Join(settingsPath, "blocker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.SwitchProviderForApp("gemini", "B"); err == nil {
+		t.Fatal("切换应失败")
+	}
+
+	if envAfter, _ := os.ReadFile(envPath); string(envAfter) != string(envBefore) {
+		t.Errorf(".env 未回滚:\n%s", envAfter)
+	}
+	if configAfter, _ := os.ReadFile(manager.GetConfigPath()); string(configAfter) != string(configBefore) {
+		t.Error("config.json 不应被修改")
+	}
+	if current := manager.GetCurrentProviderForApp("gemini"); current == nil || current.Name != "A" {
+		t.Errorf("内存中的 current 应保持为 A, got %v", current)
+	}
+	if _, err := os.Stat(manager.journalPath()); !os.IsNotExist(err) {
+		t.Error("回滚后不应残留切换日志")
+	}
+}
+
+func TestNewManagerRecoversInterruptedSwitch(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "A", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	before, _ := os.ReadFile(settingsPath)
+
+	// 模拟切换写入日志后进程被中断：live 文件已被改写
+	journal := map[string]interface{}{
+		"files": []map[string]interface{}{
+			{"path": settingsPath, "existed": true, "data": before, "perm": 0644},
+		},
+	}
+	data, _ := json.Marshal(journal)
+	if err := os.WriteFile(manager.journalPath(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(settingsPath, []byte(`{"env":{"ANTHROPIC_AUTH_TOKEN":"half-written"}}`), 0644)
+
+	if _, err := NewManagerWithDir(tmpDir); err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if after, _ := os.ReadFile(settingsPath); string(after) != string(before) {
+		t.Errorf("live 设置未恢复:\n%s", after)
+	}
+	if _, err := os.Stat(manager.journalPath()); !os.IsNotExist(err) {
+		t.Error("恢复后应删除切换日志")
+	}
+}
+
+func TestSwitchCodexPreservesConfigFormatting(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("codex", "Relay", "", "sk-relay", "https://relay.example.com/v1", "custom", "gpt-5-codex", "", "high", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("codex", "Other", "", "sk-other", "https://other.example.com/v1", "custom", "gpt-5", "", "low", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	// 在 live 文件中加入用户自己的注释和配置
+	configPath := filepath.Join(tmpDir, ".codex", "config.toml")
+	live, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("读取 live 文件失败: %v", err)
+	}
+	userContent := "# 我的 Codex 配置\napproval_policy = 'on-request'\n\n" + string(live) + `
+# 本地 MCP
+[mcp_servers.local]
+command = "npx"   # 通过 npx 启动
+args = [
+  "-y",
+  "server",
+]
+`
+	if err := os.WriteFile(configPath, []byte(userContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.SwitchProviderForApp("codex", "Other"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+	switched, _ := os.ReadFile(configPath)
+	for _, keep := range []string{"# 我的 Codex 配置\napproval_policy = 'on-request'\n", "# 本地 MCP\n[mcp_servers.local]\ncommand = \"npx\"   # 通过 npx 启动\nargs = [\n  \"-y\",\n  \"server\",\n]\n"} {
+		if !strings.Contains(string(switched), keep) {
+			t.Errorf("切换后未保留用户内容 %q:\n%s", keep, switched)
+		}
+	}
+	if !strings.Contains(string(switched), `model = "gpt-5"`) {
+		t.Errorf("受管字段未更新:\n%s", switched)
+	}
+
+	if err := manager.SwitchProviderForApp("codex", "Relay"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+	roundTrip, _ := os.ReadFile(configPath)
+	// 往返切换后只多出 Other 的 provider 段，原有内容逐字节保留
+	otherSection := sectionText(t, string(roundTrip), "[model_providers.other]")
+	if got := strings.Replace(string(roundTrip), otherSection, "", 1); got != userContent {
+		t.Errorf("往返切换改变了文件内容:\n%s", roundTrip)
+	}
+}
+
+// sectionText 返回从 header 开始到下一个空行（含）为止的段落
+func sectionText(t *testing.T, content, header string) string {
+	t.Helper()
+	start := strings.Index(content, header)
+	if start < 0 {
+		t.Fatalf("未找到 %s:\n%s", header, content)
+	}
+	end := strings.Index(content[start:], "\n\n")
+	if end < 0 {
+		return content[start:]
+	}
+	return content[start : start+end+2]
+}
+
+func TestSwitchClaudePassesThroughArbitraryEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "A", "", "sk-a", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "B", "", "sk-b", "https://b.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatalf("添加配置失败: %v", err)
+	}
+
+	// A 额外拥有 CLAUDE_CODE_USE_BEDROCK 和 API_TIMEOUT_MS
+	app := manager.config.Apps["claude"]
+	for id, p := range app.Providers {
+		if p.Name == "A" {
+			env := p.SettingsConfig["env"].(map[string]interface{})
+			env["CLAUDE_CODE_USE_BEDROCK"] = "1"
+			env["API_TIMEOUT_MS"] = "600000"
+			app.Providers[id] = p
+		}
+	}
+	if err := manager.SwitchProviderForApp("claude", "A"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	// 用户在 live 中自行添加的变量
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	var live map[string]interface{}
+	data, _ := os.ReadFile(settingsPath)
+	json.Unmarshal(data, &live)
+	liveEnv := live["env"].(map[string]interface{})
+	if liveEnv["CLAUDE_CODE_USE_BEDROCK"] != "1" || liveEnv["API_TIMEOUT_MS"] != "600000" {
+		t.Fatalf("供应商的额外变量未写入: %v", liveEnv)
+	}
+	liveEnv["HTTPS_PROXY"] = "http://proxy:8080"
+	data, _ = json.Marshal(live)
+	os.WriteFile(settingsPath, data, 0644)
+
+	if err := manager.SwitchProviderForApp("claude", "B"); err != nil {
+		t.Fatalf("切换失败: %v", err)
+	}
+
+	var settings ClaudeSettings
+	data, _ = os.ReadFile(settingsPath)
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Env.Get("ANTHROPIC_AUTH_TOKEN") != "sk-b" {
+		t.Errorf("token = %q, want sk-b", settings.Env.Get("ANTHROPIC_AUTH_TOKEN"))
+	}
+	if _, ok := settings.Env["CLAUDE_CODE_USE_BEDROCK"]; ok {
+		t.Error("切换后应移除上一个供应商拥有的变量")
+	}
+	if _, ok := settings.Env["API_TIMEOUT_MS"]; ok {
+		t.Error("切换后应移除上一个供应商拥有的变量")
+	}
+	if settings.Env.Get("HTTPS_PROXY") != "http://proxy:8080" {
+		t.Error("用户添加的变量应保留")
+	}
+
+	// 用户变量不应回填到 A
+	a, _ := manager.GetProviderForApp("claude", "A")
+	if _, ok := a.SettingsConfig["env"].(map[string]interface{})["HTTPS_PROXY"]; ok {
+		t.Error("用户变量不应回填到供应商")
+	}
+	if got := manager.config.Apps["claude"].LiveEnvKeys; !slices.Contains(got, "ANTHROPIC_AUTH_TOKEN") || slices.Contains(got, "API_TIMEOUT_MS") {
+		t.Errorf("LiveEnvKeys = %v", got)
+	}
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"encoding/json"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/secrets"
 )
 
 // CustomEndpoint 自定义端点配置（与 GUI v3.5.0+ 兼容）
@@ -16,6 +18,42 @@ type CustomEndpoint struct {
 // 仅保存在 ~/.cc-switch/config-cli.json 中，用于在 GUI 和 CLI 之间共享自定义端点等元数据
 type ProviderMeta struct {
 	CustomEndpoints map[string]CustomEndpoint `json:"custom_endpoints,omitempty"`
+	Extra           map[string]interface{}    `json:"-"` // 保存 GUI 写入的其他未知字段
+}
+
+// UnmarshalJSON 自定义反序列化，保存未知字段
+func (pm *ProviderMeta) UnmarshalJSON(data []byte) error {
+	type Alias ProviderMeta
+	aux := &struct{ *Alias }{Alias: (*Alias)(pm)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var allFields map[string]interface{}
+	if err := json.Unmarshal(data, &allFields); err != nil {
+		return err
+	}
+
+	pm.Extra = make(map[string]interface{})
+	for k, v := range allFields {
+		if k != "custom_endpoints" {
+			pm.Extra[k] = v
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON 自定义序列化，合并未知字段
+func (pm *ProviderMeta) MarshalJSON() ([]byte, error) {
+	result := make(map[string]interface{})
+	for k, v := range pm.Extra {
+		result[k] = v
+	}
+	if len(pm.CustomEndpoints) > 0 {
+		result["custom_endpoints"] = pm.CustomEndpoints
+	}
+	return json.Marshal(result)
 }
 
 // Provider 表示单个供应商配置（与 cc-switch 完全一致）
@@ -27,12 +65,16 @@ type Provider struct {
 	Category       string                 `json:"category,omitempty"`
 	CreatedAt      int64                  `json:"createdAt,omitempty"` // 毫秒时间戳
 	SortOrder      int                    `json:"sortOrder,omitempty"` // 列表排序序号，值越小越靠前
+	Meta           *ProviderMeta          `json:"meta,omitempty"`      // 元数据（自定义端点等），不写入 live 配置
 }
 
 // ProviderManager 管理单个应用的所有供应商（与 cc-switch 完全一致）
 type ProviderManager struct {
 	Providers map[string]Provider `json:"providers"` // id -> Provider
 	Current   string              `json:"current"`   // 当前激活的供应商 ID
+
+	// LiveEnvKeys 当前供应商写入 live env 的变量名，切换到其他供应商时据此移除（仅 Claude 使用）
+	LiveEnvKeys []string `json:"liveEnvKeys,omitempty"`
 }
 
 // MultiAppConfig 根配置文件结构（v2 格式，与 cc-switch 完全一致）
@@ -42,6 +84,7 @@ type MultiAppConfig struct {
 	Apps        map[string]ProviderManager `json:"-"`                     // 应用名称 -> ProviderManager (展平到顶层)
 	Mcp         *McpRoot                   `json:"mcp,omitempty"`         // MCP 配置
 	Preferences *UserPreferences           `json:"preferences,omitempty"` // 用户偏好设置
+	Secrets     *secrets.Params            `json:"secrets,omitempty"`     // Token 加密参数（未启用加密时为空）
 }
 
 // OldMultiAppConfig 旧版配置文件结构（v2-old 格式，apps 嵌套在 "apps" 键下）
@@ -71,6 +114,11 @@ func (c *MultiAppConfig) MarshalJSON() ([]byte, error) {
 		result["preferences"] = c.Preferences
 	}
 
+	// 添加加密参数
+	if c.Secrets != nil {
+		result["secrets"] = c.Secrets
+	}
+
 	return json.Marshal(result)
 }
 
@@ -107,6 +155,15 @@ func (c *MultiAppConfig) UnmarshalJSON(data []byte) error {
 		c.Preferences = &prefs
 	}
 
+	// 提取加密参数
+	if secretsData, ok := raw["secrets"]; ok {
+		var params secrets.Params
+		if err := json.Unmarshal(secretsData, &params); err != nil {
+			return err
+		}
+		c.Secrets = &params
+	}
+
 	// 初始化 Apps map
 	c.Apps = make(map[string]ProviderManager)
 
@@ -115,6 +172,7 @@ func (c *MultiAppConfig) UnmarshalJSON(data []byte) error {
 		"version":     true,
 		"mcp":         true,
 		"preferences": true,
+		"secrets":     true,
 	}
 
 	// 提取所有应用配置（除了已知字段之外的字段都视为应用）
@@ -131,16 +189,13 @@ func (c *MultiAppConfig) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// ClaudeEnv Claude 环境变量配置
-type ClaudeEnv struct {
-	AnthropicAuthToken          string `json:"ANTHROPIC_AUTH_TOKEN,omitempty"`
-	AnthropicBaseURL            string `json:"ANTHROPIC_BASE_URL,omitempty"`
-	AnthropicModel              string `json:"ANTHROPIC_MODEL,omitempty"`
-	AnthropicDefaultHaikuModel  string `json:"ANTHROPIC_DEFAULT_HAIKU_MODEL,omitempty"`
-	AnthropicDefaultSonnetModel string `json:"ANTHROPIC_DEFAULT_SONNET_MODEL,omitempty"`
-	AnthropicDefaultOpusModel   string `json:"ANTHROPIC_DEFAULT_OPUS_MODEL,omitempty"`
-	ClaudeCodeModel             string `json:"CLAUDE_CODE_MODEL,omitempty"`
-	ClaudeCodeMaxTokens         string `json:"CLAUDE_CODE_MAX_TOKENS,omitempty"`
+// ClaudeEnv Claude 环境变量配置（开放映射，保留所有变量）
+type ClaudeEnv map[string]interface{}
+
+// Get 返回字符串类型的变量值，不存在或不是字符串时返回空字符串
+func (e ClaudeEnv) Get(key string) string {
+	s, _ := e[key].(string)
+	return s
 }
 
 // ClaudePermissions Claude 权限配置
@@ -238,11 +293,25 @@ type GeminiSettings struct {
 	Extra      map[string]interface{} `json:"-"` // 保存未知字段
 }
 
-// McpApps MCP 应用启用状态
-type McpApps struct {
-	Claude bool `json:"claude"`
-	Codex  bool `json:"codex"`
-	Gemini bool `json:"gemini"`
+// McpApps MCP 应用启用状态：应用标识 -> 是否启用
+type McpApps map[string]bool
+
+// Clone 返回副本，用于编辑时不影响原服务器
+func (a McpApps) Clone() McpApps {
+	clone := make(McpApps, len(a))
+	for k, v := range a {
+		clone[k] = v
+	}
+	return clone
+}
+
+// MarshalJSON 始终输出内置应用的状态（与 GUI 的 claude/codex/gemini 字段兼容）
+func (a McpApps) MarshalJSON() ([]byte, error) {
+	result := map[string]bool{"claude": false, "codex": false, "gemini": false}
+	for k, v := range a {
+		result[k] = v
+	}
+	return json.Marshal(result)
 }
 
 // McpServer MCP 服务器定义
@@ -6,11 +6,13 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/secrets"
 )
 
 var (
-	codexModelRegex     = regexp.MustCompile(`model\s*=\s*"([^"]+)"`)
-	codexReasoningRegex = regexp.MustCompile(`model_reasoning_effort\s*=\s*"([^"]+)"`)
+	codexModelRegex     = regexp.MustCompile(`model\s*=\s*["']([^"']+)["']`)
+	codexReasoningRegex = regexp.MustCompile(`model_reasoning_effort\s*=\s*["']([^"']+)["']`)
 )
 
 func ValidateProvider(name, apiToken, baseURL string) error {
@@ -48,6 +50,13 @@ func ValidateMaxTokens(maxTokens string) error {
 }
 
 func MaskToken(token string) string {
+	if secrets.IsEncrypted(token) {
+		return "**** (已加密)"
+	}
+	if secrets.IsReference(token) {
+		// 引用本身不含机密，直接显示便于排查
+		return token
+	}
 	if len(token) <= 8 {
 		return "****"
 	}
@@ -86,7 +95,7 @@ func ExtractBaseURLFromProvider(p *Provider) string {
 	}
 
 	if configStr, ok := p.SettingsConfig["config"].(string); ok {
-		re := regexp.MustCompile(`base_url\s*=\s*"([^"]+)"`)
+		re := regexp.MustCompile(`base_url\s*=\s*["']([^"']+)["']`)
 		if matches := re.FindStringSubmatch(configStr); len(matches) > 1 {
 			return matches[1]
 		}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"crypto/sha256"
+	"os"
+	"sort"
+	"time"
+)
+
+// FileStamp 文件指纹，mtime 与大小未变时复用上次的哈希
+type FileStamp struct {
+	Exists  bool
+	ModTime time.Time
+	Size    int64
+	Hash    [sha256.Size]byte
+}
+
+// FileSnapshot 一组文件的指纹，用于检测外部程序（GUI 或其他 ccs 进程）的修改
+type FileSnapshot map[string]FileStamp
+
+// WatchedPaths 返回需要监视外部修改的文件：config.json 以及所有应用的 live 文件
+func (m *Manager) WatchedPaths() []string {
+	paths := []string{m.configPath}
+	for _, app := range m.Apps() {
+		live, err := app.LivePaths(m)
+		if err != nil {
+			continue
+		}
+		paths = append(paths, live...)
+	}
+	return paths
+}
+
+// TakeSnapshot 计算文件指纹，prev 中 mtime 与大小一致的文件不再重新读取
+func TakeSnapshot(paths []string, prev FileSnapshot) FileSnapshot {
+	snapshot := make(FileSnapshot, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			snapshot[path] = FileStamp{}
+			continue
+		}
+		stamp := FileStamp{Exists: true, ModTime: info.ModTime(), Size: info.Size()}
+		if old, ok := prev[path]; ok && old.Exists && old.ModTime.Equal(stamp.ModTime) && old.Size == stamp.Size {
+			stamp.Hash = old.Hash
+		} else if data, err := os.ReadFile(path); err == nil {
+			stamp.Hash = sha256.Sum256(data)
+		}
+		snapshot[path] = stamp
+	}
+	return snapshot
+}
+
+// Changed 返回 newer 中内容与 s 不同的文件；仅 mtime 变化（如 touch）不算修改
+func (s FileSnapshot) Changed(newer FileSnapshot) []string {
+	var changed []string
+	for path, stamp := range newer {
+		old, ok := s[path]
+		if !ok {
+			// 新加入监视的文件（如新安装的自定义应用）不视为外部修改
+			continue
+		}
+		if old.Exists != stamp.Exists || old.Hash != stamp.Hash {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSnapshotDetectsExternalChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManagerWithDir(tmpDir)
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := manager.AddProviderForApp("claude", "p1", "", "sk-1", "https://a.example.com", "custom", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SwitchProvider("p1"); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := manager.WatchedPaths()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	if !slices.Contains(paths, manager.GetConfigPath()) || !slices.Contains(paths, settingsPath) {
+		t.Fatalf("WatchedPaths() = %v", paths)
+	}
+
+	before := TakeSnapshot(paths, nil)
+	if changed := before.Changed(TakeSnapshot(paths, before)); len(changed) != 0 {
+		t.Errorf("未修改时 Changed() = %v", changed)
+	}
+
+	// 仅修改 mtime 不算外部修改
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(manager.GetConfigPath(), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if changed := before.Changed(TakeSnapshot(paths, before)); len(changed) != 0 {
+		t.Errorf("touch 后 Changed() = %v", changed)
+	}
+
+	// 外部进程重写 live 文件并删除 config.json
+	if err := os.WriteFile(settingsPath, []byte(`{"env":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(manager.GetConfigPath())
+	want := []string{manager.GetConfigPath(), settingsPath}
+	slices.Sort(want)
+	if changed := before.Changed(TakeSnapshot(paths, before)); !slices.Equal(changed, want) {
+		t.Errorf("Changed() = %v, want %v", changed, want)
+	}
+}
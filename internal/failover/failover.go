@@ -0,0 +1,173 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+)
+
+const (
+	// DefaultTimeout 健康检查默认超时
+	DefaultTimeout = 5 * time.Second
+	// DefaultInterval watch 模式默认检查间隔
+	DefaultInterval = 60 * time.Second
+	// LogFileName 切换日志文件名（位于配置目录下）
+	LogFileName = "failover.log"
+)
+
+// Options 故障转移参数
+type Options struct {
+	App     string
+	Timeout time.Duration
+	DryRun  bool
+	LogPath string       // 为空时使用 <配置目录>/failover.log
+	Client  *http.Client // 为空时使用默认客户端
+}
+
+// Event 一次故障转移切换记录
+type Event struct {
+	Time   time.Time
+	App    string
+	From   string
+	To     string
+	Reason string
+	DryRun bool
+}
+
+// String 返回日志行格式
+func (e Event) String() string {
+	line := fmt.Sprintf("%s [%s] %s -> %s: %s", e.Time.Format(time.RFC3339), e.App, e.From, e.To, e.Reason)
+	if e.DryRun {
+		line += " (dry-run)"
+	}
+	return line
+}
+
+// CheckHealth 检查端点是否健康：能建立连接且返回非 5xx 响应（401/404 等视为服务在线）
+func CheckHealth(ctx context.Context, client *http.Client, url string, timeout time.Duration) error {
+	if url == "" {
+		return fmt.Errorf("未配置 Base URL")
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "cc-switch-cli-failover")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RunOnce 检查当前供应商，不健康时按 SortOrder 顺序切换到下一个健康的供应商
+// 当前供应商健康时返回 nil 事件；没有可用供应商时返回错误
+func RunOnce(ctx context.Context, manager *config.Manager, opts Options) (*Event, error) {
+	opts = normalizeOptions(manager, opts)
+
+	providers := manager.ListProvidersForApp(opts.App)
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("应用 '%s' 没有任何配置", opts.App)
+	}
+
+	current := manager.GetCurrentProviderForApp(opts.App)
+	start := 0
+	fromName := "(无)"
+	reason := "当前未激活任何配置"
+
+	if current != nil {
+		fromName = current.Name
+		baseURL := config.ExtractAppBaseURLFromProvider(opts.App, current)
+		err := CheckHealth(ctx, opts.Client, baseURL, opts.Timeout)
+		if err == nil {
+			return nil, nil
+		}
+		reason = fmt.Sprintf("%s 不可用: %v", baseURL, err)
+		for i, p := range providers {
+			if p.ID == current.ID {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	// 从当前供应商的下一个开始环形查找
+	for offset := 0; offset < len(providers); offset++ {
+		candidate := providers[(start+offset)%len(providers)]
+		if current != nil && candidate.ID == current.ID {
+			continue
+		}
+		baseURL := config.ExtractAppBaseURLFromProvider(opts.App, &candidate)
+		if err := CheckHealth(ctx, opts.Client, baseURL, opts.Timeout); err != nil {
+			continue
+		}
+
+		event := &Event{
+			Time:   time.Now(),
+			App:    opts.App,
+			From:   fromName,
+			To:     candidate.Name,
+			Reason: reason,
+			DryRun: opts.DryRun,
+		}
+
+		if !opts.DryRun {
+			if err := manager.SwitchProviderForApp(opts.App, candidate.Name); err != nil {
+				return nil, fmt.Errorf("切换到 %s 失败: %w", candidate.Name, err)
+			}
+		}
+
+		if err := appendLog(opts.LogPath, *event); err != nil {
+			return event, fmt.Errorf("写入切换日志失败: %w", err)
+		}
+		return event, nil
+	}
+
+	return nil, fmt.Errorf("%s，且没有其他健康的配置可切换", reason)
+}
+
+func normalizeOptions(manager *config.Manager, opts Options) Options {
+	if opts.App == "" {
+		opts.App = "claude"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{}
+	}
+	if opts.LogPath == "" {
+		opts.LogPath = filepath.Join(filepath.Dir(manager.GetConfigPath()), LogFileName)
+	}
+	return opts
+}
+
+// appendLog 追加一行切换日志
+func appendLog(path string, event Event) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(event.String() + "\n")
+	return err
+}
@@ -0,0 +1,108 @@
+package failover
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+)
+
+func newTestManager(t *testing.T, urls ...string) *config.Manager {
+	t.Helper()
+	manager, err := config.NewManagerWithDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	for i, url := range urls {
+		name := string(rune('A' + i))
+		if err := manager.AddProviderForApp("claude", name, "", "sk-"+name, url, "custom", "", "", "", ""); err != nil {
+			t.Fatalf("添加配置失败: %v", err)
+		}
+	}
+	return manager
+}
+
+func TestRunOnceSwitchesToNextHealthy(t *testing.T) {
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	deadURL := dead.URL
+	dead.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer healthy.Close()
+
+	manager := newTestManager(t, deadURL, broken.URL, healthy.URL)
+	logPath := filepath.Join(t.TempDir(), "failover.log")
+
+	event, err := RunOnce(context.Background(), manager, Options{App: "claude", LogPath: logPath})
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if event == nil || event.From != "A" || event.To != "C" {
+		t.Fatalf("event = %+v, want A -> C", event)
+	}
+	if current := manager.GetCurrentProviderForApp("claude"); current.Name != "C" {
+		t.Errorf("current = %s, want C", current.Name)
+	}
+
+	data, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(data), "A -> C") {
+		t.Errorf("日志内容 = %q", string(data))
+	}
+
+	// 当前已健康，不应再切换
+	event, err = RunOnce(context.Background(), manager, Options{App: "claude", LogPath: logPath})
+	if err != nil || event != nil {
+		t.Errorf("健康时不应切换: event=%+v err=%v", event, err)
+	}
+}
+
+func TestRunOnceDryRun(t *testing.T) {
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	deadURL := dead.URL
+	dead.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+
+	manager := newTestManager(t, deadURL, healthy.URL)
+	logPath := filepath.Join(t.TempDir(), "failover.log")
+
+	event, err := RunOnce(context.Background(), manager, Options{App: "claude", DryRun: true, LogPath: logPath})
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if event == nil || !event.DryRun || event.To != "B" {
+		t.Fatalf("event = %+v", event)
+	}
+	if current := manager.GetCurrentProviderForApp("claude"); current.Name != "A" {
+		t.Errorf("dry-run 不应切换, current = %s", current.Name)
+	}
+	data, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(data), "(dry-run)") {
+		t.Errorf("日志内容 = %q", string(data))
+	}
+}
+
+func TestRunOnceNoHealthyProvider(t *testing.T) {
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	deadURL := dead.URL
+	dead.Close()
+
+	manager := newTestManager(t, deadURL, deadURL)
+	_, err := RunOnce(context.Background(), manager, Options{App: "claude", LogPath: filepath.Join(t.TempDir(), "f.log")})
+	if err == nil {
+		t.Error("没有健康配置时应该返回错误")
+	}
+}
@@ -0,0 +1,354 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/writeset"
+)
+
+// MaxEntries 最多保留的操作记录数，超出时删除最早的记录
+const MaxEntries = 50
+
+// ErrNothingToUndo 没有可撤销的操作
+var ErrNothingToUndo = errors.New("没有可撤销的操作")
+
+// ErrNothingToRedo 没有可重做的操作
+var ErrNothingToRedo = errors.New("没有可重做的操作")
+
+// ErrDiverged 文件在操作之后又被修改，撤销/重做会覆盖这些修改
+var ErrDiverged = errors.New("文件已在该操作之后被修改")
+
+// FileState 文件在某一时刻的状态
+type FileState struct {
+	Existed bool        `json:"existed"`
+	Data    []byte      `json:"data,omitempty"`
+	Perm    os.FileMode `json:"perm,omitempty"`
+}
+
+// equal 比较内容是否相同（权限不参与比较）
+func (s FileState) equal(o FileState) bool {
+	return s.Existed == o.Existed && bytes.Equal(s.Data, o.Data)
+}
+
+// FileChange 一次操作中单个文件修改前后的状态
+type FileChange struct {
+	Path   string    `json:"path"`
+	Before FileState `json:"before"`
+	After  FileState `json:"after"`
+}
+
+// Entry 一条操作记录；文件快照单独保存在 <Seq>.json 中
+type Entry struct {
+	Seq     int       `json:"seq"`
+	Time    time.Time `json:"time"`
+	Summary string    `json:"summary"`
+	Paths   []string  `json:"paths"`
+	Undone  bool      `json:"undone,omitempty"`
+}
+
+// index 记录列表，按 Seq 升序排列；已撤销的记录总是位于末尾
+type index struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Store 操作历史，保存在配置目录的 history/ 下
+// 撤销/重做通过 writeset 写入文件，中断后可由同一日志恢复
+type Store struct {
+	dir         string
+	journalPath string
+}
+
+// New 创建操作历史，journalPath 为恢复文件时使用的写入日志
+func New(dir, journalPath string) *Store {
+	return &Store{dir: dir, journalPath: journalPath}
+}
+
+// Capture 读取一组文件的当前状态，读取失败的文件视为不存在
+func Capture(paths []string) map[string]FileState {
+	states := make(map[string]FileState, len(paths))
+	for _, path := range paths {
+		if _, ok := states[path]; ok {
+			continue
+		}
+		states[path] = captureFile(path)
+	}
+	return states
+}
+
+func captureFile(path string) FileState {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return FileState{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileState{}
+	}
+	return FileState{Existed: true, Data: data, Perm: info.Mode().Perm()}
+}
+
+// Diff 返回 before 与 after 中内容不同的文件，按路径排序
+func Diff(before, after map[string]FileState) []FileChange {
+	var changes []FileChange
+	for path, b := range before {
+		a, ok := after[path]
+		if !ok || b.equal(a) {
+			continue
+		}
+		changes = append(changes, FileChange{Path: path, Before: b, After: a})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// Record 新增一条记录；尚未重做的已撤销记录会被丢弃
+func (s *Store) Record(summary string, changes []FileChange) (*Entry, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	idx, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	kept := idx.Entries[:0]
+	for _, e := range idx.Entries {
+		if e.Undone {
+			os.Remove(s.entryPath(e.Seq))
+			continue
+		}
+		kept = append(kept, e)
+	}
+	idx.Entries = kept
+
+	entry := Entry{Seq: 1, Time: time.Now(), Summary: summary}
+	if n := len(idx.Entries); n > 0 {
+		entry.Seq = idx.Entries[n-1].Seq + 1
+	}
+	for _, c := range changes {
+		entry.Paths = append(entry.Paths, c.Path)
+	}
+
+	// 快照中包含 Token，仅当前用户可读
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建历史目录失败: %w", err)
+	}
+	if err := utils.WriteJSONFile(s.entryPath(entry.Seq), changes, 0600); err != nil {
+		return nil, fmt.Errorf("写入操作记录失败: %w", err)
+	}
+	idx.Entries = append(idx.Entries, entry)
+
+	if over := len(idx.Entries) - MaxEntries; over > 0 {
+		for _, e := range idx.Entries[:over] {
+			os.Remove(s.entryPath(e.Seq))
+		}
+		idx.Entries = idx.Entries[over:]
+	}
+
+	if err := s.save(idx); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List 返回所有记录，按时间从早到晚排列
+func (s *Store) List() ([]Entry, error) {
+	idx, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return idx.Entries, nil
+}
+
+// Undo 从最近一条开始撤销 n 条记录，将文件恢复为操作前的状态
+// 文件在操作之后又被修改时返回 ErrDiverged，force 为 true 时仍然覆盖
+func (s *Store) Undo(n int, force bool) ([]Entry, error) {
+	idx, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	// 已撤销的记录位于末尾，其前面的 n 条为本次撤销的目标（从新到旧）
+	end := len(idx.Entries)
+	for end > 0 && idx.Entries[end-1].Undone {
+		end--
+	}
+	if end == 0 {
+		return nil, ErrNothingToUndo
+	}
+	if n <= 0 {
+		n = 1
+	}
+	if n > end {
+		return nil, fmt.Errorf("只有 %d 条可撤销的操作", end)
+	}
+
+	var targets []int
+	for i := end - 1; i >= end-n; i-- {
+		targets = append(targets, i)
+	}
+	if err := s.revert(idx, targets, true, force); err != nil {
+		return nil, err
+	}
+
+	undone := make([]Entry, len(targets))
+	for i, t := range targets {
+		undone[i] = idx.Entries[t]
+	}
+	return undone, nil
+}
+
+// Redo 按撤销的相反顺序重做 n 条记录，将文件恢复为操作后的状态
+func (s *Store) Redo(n int, force bool) ([]Entry, error) {
+	idx, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	start := len(idx.Entries)
+	for start > 0 && idx.Entries[start-1].Undone {
+		start--
+	}
+	available := len(idx.Entries) - start
+	if available == 0 {
+		return nil, ErrNothingToRedo
+	}
+	if n <= 0 {
+		n = 1
+	}
+	if n > available {
+		return nil, fmt.Errorf("只有 %d 条可重做的操作", available)
+	}
+
+	var targets []int
+	for i := start; i < start+n; i++ {
+		targets = append(targets, i)
+	}
+	if err := s.revert(idx, targets, false, force); err != nil {
+		return nil, err
+	}
+
+	redone := make([]Entry, len(targets))
+	for i, t := range targets {
+		redone[i] = idx.Entries[t]
+	}
+	return redone, nil
+}
+
+// Clear 删除所有记录
+func (s *Store) Clear() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return fmt.Errorf("清除操作历史失败: %w", err)
+	}
+	return nil
+}
+
+// revert 按顺序应用 targets 中记录的文件状态（undo 时恢复 Before，否则恢复 After），
+// 全部文件作为一个整体提交，成功后更新记录的撤销状态
+func (s *Store) revert(idx *index, targets []int, undo bool, force bool) error {
+	// expected 为执行前各文件应处的状态，final 为执行后的状态
+	expected := make(map[string]FileState)
+	final := make(map[string]FileState)
+	var order []string
+	for _, t := range targets {
+		changes, err := s.loadChanges(idx.Entries[t].Seq)
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			from, to := c.After, c.Before
+			if !undo {
+				from, to = c.Before, c.After
+			}
+			if _, seen := expected[c.Path]; !seen {
+				expected[c.Path] = from
+				order = append(order, c.Path)
+			}
+			final[c.Path] = to
+		}
+	}
+
+	if !force {
+		for _, path := range order {
+			if !captureFile(path).equal(expected[path]) {
+				return fmt.Errorf("%w: %s", ErrDiverged, path)
+			}
+		}
+	}
+
+	ws := writeset.New(s.journalPath)
+	for _, path := range order {
+		state := final[path]
+		if !state.Existed {
+			ws.Remove(path)
+			continue
+		}
+		perm := state.Perm
+		if perm == 0 {
+			perm = 0600
+		}
+		ws.Write(path, state.Data, perm)
+	}
+	if err := ws.Commit(); err != nil {
+		return err
+	}
+
+	for _, t := range targets {
+		idx.Entries[t].Undone = undo
+	}
+	return s.save(idx)
+}
+
+func (s *Store) indexPath() string {
+	return filepath.Join(s.dir, "index.json")
+}
+
+func (s *Store) entryPath(seq int) string {
+	return filepath.Join(s.dir, strconv.Itoa(seq)+".json")
+}
+
+func (s *Store) load() (*index, error) {
+	idx := &index{}
+	data, err := os.ReadFile(s.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取操作历史失败: %w", err)
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("解析操作历史失败: %w", err)
+	}
+	return idx, nil
+}
+
+func (s *Store) save(idx *index) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("创建历史目录失败: %w", err)
+	}
+	if err := utils.WriteJSONFile(s.indexPath(), idx, 0600); err != nil {
+		return fmt.Errorf("写入操作历史失败: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) loadChanges(seq int) ([]FileChange, error) {
+	data, err := os.ReadFile(s.entryPath(seq))
+	if err != nil {
+		return nil, fmt.Errorf("读取操作记录 #%d 失败: %w", seq, err)
+	}
+	var changes []FileChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("解析操作记录 #%d 失败: %w", seq, err)
+	}
+	return changes, nil
+}
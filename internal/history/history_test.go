@@ -0,0 +1,131 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "<missing>"
+	}
+	return string(data)
+}
+
+// record 执行 fn 并将 paths 的变化记录为一条历史
+func record(t *testing.T, s *Store, summary string, paths []string, fn func()) {
+	t.Helper()
+	before := Capture(paths)
+	fn()
+	if _, err := s.Record(summary, Diff(before, Capture(paths))); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+}
+
+func TestUndoRedoRestoresFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := New(filepath.Join(dir, "history"), filepath.Join(dir, "switch.journal"))
+	cfg := filepath.Join(dir, "config.json")
+	live := filepath.Join(dir, "settings.json")
+	paths := []string{cfg, live}
+
+	writeFile(t, cfg, "v1")
+	record(t, s, "first", paths, func() {
+		writeFile(t, cfg, "v2")
+		writeFile(t, live, "live")
+	})
+	record(t, s, "second", paths, func() { writeFile(t, cfg, "v3") })
+	record(t, s, "noop", paths, func() {})
+
+	entries, _ := s.List()
+	if len(entries) != 2 {
+		t.Fatalf("无变化的操作不应记录, got %d entries", len(entries))
+	}
+
+	undone, err := s.Undo(2, false)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if len(undone) != 2 || undone[0].Summary != "second" {
+		t.Errorf("应从最新的记录开始撤销, got %+v", undone)
+	}
+	if got := readFile(t, cfg); got != "v1" {
+		t.Errorf("config = %q, want v1", got)
+	}
+	if got := readFile(t, live); got != "<missing>" {
+		t.Errorf("操作前不存在的文件应被删除, got %q", got)
+	}
+	if _, err := s.Undo(1, false); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() error = %v, want ErrNothingToUndo", err)
+	}
+
+	if _, err := s.Redo(1, false); err != nil {
+		t.Fatalf("Redo() error = %v", err)
+	}
+	if readFile(t, cfg) != "v2" || readFile(t, live) != "live" {
+		t.Errorf("重做后 config=%q live=%q", readFile(t, cfg), readFile(t, live))
+	}
+
+	// 新操作会丢弃尚未重做的记录
+	record(t, s, "third", paths, func() { writeFile(t, cfg, "v4") })
+	if _, err := s.Redo(1, false); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Redo() error = %v, want ErrNothingToRedo", err)
+	}
+	entries, _ = s.List()
+	if len(entries) != 2 || entries[1].Summary != "third" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestUndoRefusesDivergedFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := New(filepath.Join(dir, "history"), filepath.Join(dir, "switch.journal"))
+	cfg := filepath.Join(dir, "config.json")
+
+	writeFile(t, cfg, "v1")
+	record(t, s, "edit", []string{cfg}, func() { writeFile(t, cfg, "v2") })
+	writeFile(t, cfg, "edited elsewhere")
+
+	if _, err := s.Undo(1, false); !errors.Is(err, ErrDiverged) {
+		t.Fatalf("Undo() error = %v, want ErrDiverged", err)
+	}
+	if got := readFile(t, cfg); got != "edited elsewhere" {
+		t.Errorf("拒绝撤销时不应修改文件, got %q", got)
+	}
+
+	if _, err := s.Undo(1, true); err != nil {
+		t.Fatalf("Undo(force) error = %v", err)
+	}
+	if got := readFile(t, cfg); got != "v1" {
+		t.Errorf("config = %q, want v1", got)
+	}
+}
+
+func TestRecordKeepsMaxEntries(t *testing.T) {
+	dir := t.TempDir()
+	s := New(filepath.Join(dir, "history"), filepath.Join(dir, "switch.journal"))
+	cfg := filepath.Join(dir, "config.json")
+
+	for i := 0; i < MaxEntries+3; i++ {
+		content := string(rune('a' + i%26))
+		record(t, s, "op", []string{cfg}, func() { writeFile(t, cfg, content+readFile(t, cfg)) })
+	}
+	entries, _ := s.List()
+	if len(entries) != MaxEntries || entries[0].Seq != 4 {
+		t.Errorf("len = %d, first seq = %d", len(entries), entries[0].Seq)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "history", "1.json")); !os.IsNotExist(err) {
+		t.Errorf("超出上限的记录文件应被删除")
+	}
+}
@@ -0,0 +1,138 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLockTimeout is returned when the file lock is still held after the timeout
+var ErrLockTimeout = errors.New("timed out waiting for file lock")
+
+// lockPollInterval is the delay between non-blocking lock attempts
+const lockPollInterval = 50 * time.Millisecond
+
+// FileLock is an OS advisory lock (flock / LockFileEx) on a dedicated lock file.
+// Unlike the PID lock it is released by the kernel when the holder exits,
+// so it never goes stale.
+type FileLock struct {
+	path string
+	file *os.File
+}
+
+// NewFileLock creates a file lock for the given lock file path
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+// Path returns the lock file path
+func (l *FileLock) Path() string {
+	return l.path
+}
+
+// Lock acquires the lock, waiting up to timeout for other holders
+func (l *FileLock) Lock(timeout time.Duration) error {
+	if l.file != nil {
+		return nil
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("failed to lock %s: %w", l.path, err)
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			if pid, err := readPID(l.path); err == nil {
+				return fmt.Errorf("%w: %s (held by PID %d)", ErrLockTimeout, l.path, pid)
+			}
+			return fmt.Errorf("%w: %s", ErrLockTimeout, l.path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	// Record the holder so `ccs lock status` can report it
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	l.file = f
+	return nil
+}
+
+// Unlock releases the lock and clears the recorded holder
+func (l *FileLock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+	l.file.Truncate(0)
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+// HolderInfo describes the current state of a lock file
+type HolderInfo struct {
+	Exists bool // lock file exists
+	Held   bool // another process currently holds the lock
+	PID    int  // recorded holder PID, 0 if none
+	Alive  bool // recorded holder process is still running
+}
+
+// Inspect reports whether the lock at path is held and by whom
+func Inspect(path string) (HolderInfo, error) {
+	var info HolderInfo
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return info, nil
+		}
+		return info, err
+	}
+	defer f.Close()
+	info.Exists = true
+
+	ok, err := tryLockFile(f)
+	if err != nil {
+		return info, err
+	}
+	if ok {
+		unlockFile(f)
+	} else {
+		info.Held = true
+	}
+
+	if pid, err := readPID(path); err == nil {
+		info.PID = pid
+		info.Alive = ProcessAlive(pid)
+	}
+	return info, nil
+}
+
+// readPID reads the PID recorded in a lock file
+func readPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid PID in lock file: %w", err)
+	}
+	return pid, nil
+}
@@ -0,0 +1,44 @@
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLockExcludesOtherHolders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json.lock")
+
+	first := NewFileLock(path)
+	if err := first.Lock(time.Second); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	info, err := Inspect(path)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if !info.Held || info.PID != os.Getpid() || !info.Alive {
+		t.Errorf("Inspect() = %+v", info)
+	}
+
+	second := NewFileLock(path)
+	if err := second.Lock(100 * time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("锁被占用时应超时, got %v", err)
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if err := second.Lock(time.Second); err != nil {
+		t.Fatalf("释放后应能获取锁: %v", err)
+	}
+	second.Unlock()
+
+	info, _ = Inspect(path)
+	if info.Held || info.PID != 0 {
+		t.Errorf("释放后 Inspect() = %+v", info)
+	}
+}
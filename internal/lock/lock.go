@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/YangQing-Lin/cc-switch-cli/internal/portable"
@@ -42,8 +43,15 @@ func (l *Lock) TryAcquire() (bool, error) {
 
 	// Check if lock file exists
 	if info, err := os.Stat(l.lockPath); err == nil {
-		// Lock file exists, check if it's stale
-		if time.Since(info.ModTime()) > StaleLockTimeout {
+		// Lock file exists, check if it's stale: a recorded holder is trusted
+		// only while its process is alive; otherwise fall back to the timeout
+		if pid, err := l.GetPID(); err == nil {
+			if !ProcessAlive(pid) {
+				os.Remove(l.lockPath)
+			} else {
+				return false, nil
+			}
+		} else if time.Since(info.ModTime()) > StaleLockTimeout {
 			// Stale lock, remove it
 			os.Remove(l.lockPath)
 		} else {
@@ -113,6 +121,11 @@ func (l *Lock) Touch() error {
 	return os.Chtimes(l.lockPath, now, now)
 }
 
+// Path returns the lock file path
+func (l *Lock) Path() string {
+	return l.lockPath
+}
+
 // GetPID returns the PID stored in the lock file
 func (l *Lock) GetPID() (int, error) {
 	data, err := os.ReadFile(l.lockPath)
@@ -120,7 +133,7 @@ func (l *Lock) GetPID() (int, error) {
 		return 0, err
 	}
 
-	pid, err := strconv.Atoi(string(data))
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
 	if err != nil {
 		return 0, fmt.Errorf("invalid PID in lock file: %w", err)
 	}
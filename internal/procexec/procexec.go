@@ -0,0 +1,88 @@
+package procexec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// forwardedSignals 转发给子进程的信号
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// MergeEnv 在 base（形如 KEY=VALUE）上覆盖 overrides，返回排序后的结果
+func MergeEnv(base []string, overrides map[string]string) []string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for _, kv := range base {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+
+	result := make([]string, 0, len(merged))
+	for key, value := range merged {
+		result = append(result, key+"="+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Run 启动子进程并等待结束，标准输入输出直通，收到的信号转发给子进程
+// 返回子进程的退出码；子进程被信号终止时按 shell 约定返回 128+信号值
+func Run(name string, args []string, env []string) (int, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return 127, fmt.Errorf("找不到命令 %s: %w", name, err)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// 先注册信号，避免子进程启动后、转发开始前的信号终止父进程
+	sigCh := make(chan os.Signal, 4)
+	signal.Notify(sigCh, forwardedSignals...)
+	defer signal.Stop(sigCh)
+
+	if err := cmd.Start(); err != nil {
+		return 126, fmt.Errorf("启动命令失败: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				_ = cmd.Process.Signal(sig) // 部分平台不支持转发，忽略错误
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+	if err == nil {
+		return 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 1, err
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), nil
+	}
+	return exitErr.ExitCode(), nil
+}
@@ -0,0 +1,58 @@
+package procexec
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMergeEnv(t *testing.T) {
+	env := MergeEnv([]string{"A=1", "B=2", "INVALID"}, map[string]string{"B": "override", "C": "3"})
+	got := strings.Join(env, ",")
+	if got != "A=1,B=override,C=3" {
+		t.Errorf("MergeEnv() = %s", got)
+	}
+}
+
+func TestRunExitCodeAndEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 sh 测试")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	env := MergeEnv(os.Environ(), map[string]string{"CCS_EXEC_TEST": "injected"})
+
+	code, err := Run("sh", []string{"-c", `printf %s "$CCS_EXEC_TEST" > "$0"; exit 7`, out}, env)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if code != 7 {
+		t.Errorf("exit code = %d, want 7", code)
+	}
+	data, _ := os.ReadFile(out)
+	if string(data) != "injected" {
+		t.Errorf("子进程环境变量 = %q, want injected", data)
+	}
+}
+
+func TestRunSignaled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 sh 测试")
+	}
+	code, err := Run("sh", []string{"-c", "kill -TERM $$"}, os.Environ())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if code != 128+15 {
+		t.Errorf("exit code = %d, want 143", code)
+	}
+}
+
+func TestRunCommandNotFound(t *testing.T) {
+	code, err := Run("ccs-definitely-missing-command", nil, os.Environ())
+	if err == nil || code != 127 {
+		t.Errorf("Run() = %d, %v; want 127 and error", code, err)
+	}
+}
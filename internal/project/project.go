@@ -0,0 +1,120 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// FileName 项目配置文件名
+const FileName = ".ccs.toml"
+
+// Apps 支持固定配置的应用，按展示顺序排列
+var Apps = []string{"claude", "codex", "gemini"}
+
+// File 项目配置文件，记录各应用固定使用的配置名称
+type File struct {
+	Claude string `toml:"claude,omitempty"`
+	Codex  string `toml:"codex,omitempty"`
+	Gemini string `toml:"gemini,omitempty"`
+
+	Path string `toml:"-"` // 文件所在路径
+}
+
+// Find 从 dir 开始逐级向上查找项目配置文件，未找到时返回空字符串
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Discover 从当前工作目录开始查找并加载项目配置，未找到时返回 nil
+func Discover() (*File, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	path, err := Find(cwd)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Load 读取指定路径的项目配置
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	f.Path = path
+	return &f, nil
+}
+
+// Save 写入项目配置到 f.Path
+func (f *File) Save() error {
+	if f.Path == "" {
+		return errors.New("项目配置路径为空")
+	}
+	data, err := toml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("序列化项目配置失败: %w", err)
+	}
+	return utils.AtomicWriteFile(f.Path, data, 0644)
+}
+
+// Get 返回指定应用固定的配置名称，未固定时返回空字符串
+func (f *File) Get(appName string) string {
+	if f == nil {
+		return ""
+	}
+	switch appName {
+	case "claude":
+		return f.Claude
+	case "codex":
+		return f.Codex
+	case "gemini":
+		return f.Gemini
+	}
+	return ""
+}
+
+// Set 设置指定应用固定的配置名称，name 为空表示取消固定
+func (f *File) Set(appName, name string) error {
+	switch appName {
+	case "claude":
+		f.Claude = name
+	case "codex":
+		f.Codex = name
+	case "gemini":
+		f.Gemini = name
+	default:
+		return fmt.Errorf("不支持的应用: %s", appName)
+	}
+	return nil
+}
+
+// IsEmpty 判断是否没有任何固定配置
+func (f *File) IsEmpty() bool {
+	return f.Claude == "" && f.Codex == "" && f.Gemini == ""
+}
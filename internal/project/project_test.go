@@ -0,0 +1,69 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindWalksUp(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := Find(nested)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	// 临时目录的上级可能恰好存在项目文件，只要求不在 root 内
+	if strings.HasPrefix(path, root) {
+		t.Errorf("未创建项目文件时不应找到 %s", path)
+	}
+
+	want := filepath.Join(root, "a", FileName)
+	if err := os.WriteFile(want, []byte(`claude = "corp-relay"`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path, err = Find(nested)
+	if err != nil || path != want {
+		t.Fatalf("Find() = %q, %v; want %q", path, err, want)
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if f.Get("claude") != "corp-relay" || f.Get("codex") != "" {
+		t.Errorf("Load() = %+v", f)
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	f := &File{Path: path}
+	if err := f.Set("codex", "dev"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("unknown", "x"); err == nil {
+		t.Error("未知应用应返回错误")
+	}
+	if err := f.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Codex != "dev" || loaded.Claude != "" {
+		t.Errorf("loaded = %+v", loaded)
+	}
+
+	_ = loaded.Set("codex", "")
+	if !loaded.IsEmpty() {
+		t.Error("取消固定后应为空")
+	}
+}
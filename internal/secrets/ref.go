@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ResolveFunc 解析引用中 scheme 之后的部分，返回实际的 Token
+type ResolveFunc func(ctx context.Context, target string) (string, error)
+
+// CommandTimeout cmd:/pass: 引用执行外部命令的超时时间
+var CommandTimeout = 10 * time.Second
+
+// backends 已注册的引用后端（scheme -> 解析函数）
+var backends = map[string]ResolveFunc{
+	"env":  resolveEnv,
+	"file": resolveFile,
+	"cmd":  resolveCommand,
+	"pass": resolvePass,
+}
+
+// RegisterBackend 注册（或覆盖）一个引用后端
+func RegisterBackend(scheme string, fn ResolveFunc) {
+	backends[scheme] = fn
+}
+
+// Backends 返回已注册的后端名称（已排序）
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseReference 拆分引用为 scheme 和目标；不是已注册的引用时返回 false
+func parseReference(value string) (string, string, bool) {
+	scheme, target, ok := strings.Cut(value, ":")
+	if !ok || target == "" {
+		return "", "", false
+	}
+	if _, exists := backends[scheme]; !exists {
+		return "", "", false
+	}
+	return scheme, target, true
+}
+
+// IsReference 判断值是否为外部密钥引用，如 env:MY_KEY、file:/run/secrets/x、cmd:pass show key
+func IsReference(value string) bool {
+	_, _, ok := parseReference(value)
+	return ok
+}
+
+// Resolve 解析外部密钥引用；不是引用时原样返回
+func Resolve(ctx context.Context, value string) (string, error) {
+	scheme, target, ok := parseReference(value)
+	if !ok {
+		return value, nil
+	}
+
+	resolved, err := backends[scheme](ctx, target)
+	if err != nil {
+		return "", fmt.Errorf("解析引用 %s 失败: %w", value, err)
+	}
+	if resolved == "" {
+		return "", fmt.Errorf("解析引用 %s 失败: 结果为空", value)
+	}
+	return resolved, nil
+}
+
+// resolveEnv 读取环境变量
+func resolveEnv(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("环境变量 %s 未设置", name)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// resolveFile 读取文件内容（去除首尾空白），支持 ~ 开头的路径
+func resolveFile(_ context.Context, path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = home + path[1:]
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// resolveCommand 通过 shell 执行命令，取标准输出（去除首尾空白）
+func resolveCommand(ctx context.Context, command string) (string, error) {
+	var shell, flag string
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	} else {
+		shell, flag = "sh", "-c"
+	}
+	out, err := runCommand(ctx, shell, flag, command)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// resolvePass 从 pass 密码库读取，只取第一行（pass 约定第一行为密码）
+func resolvePass(ctx context.Context, name string) (string, error) {
+	out, err := runCommand(ctx, "pass", "show", name)
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(out, "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// runCommand 执行外部命令，失败时附带标准错误输出
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, CommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
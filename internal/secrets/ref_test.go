@@ -0,0 +1,83 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestIsReference(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"env:MY_KEY", true},
+		{"file:/run/secrets/x", true},
+		{"cmd:pass show relay/key", true},
+		{"pass:relay/key", true},
+		{"env:", false},
+		{"sk-abc:def", false},
+		{"enc:v1:xxxx", false},
+		{"sk-plain-token", false},
+	}
+	for _, tt := range tests {
+		if got := IsReference(tt.value); got != tt.want {
+			t.Errorf("IsReference(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestResolveEnvAndFile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Setenv("CCS_TEST_RELAY_KEY", "sk-from-env")
+	if got, err := Resolve(ctx, "env:CCS_TEST_RELAY_KEY"); err != nil || got != "sk-from-env" {
+		t.Errorf("Resolve(env) = %q, %v", got, err)
+	}
+	if _, err := Resolve(ctx, "env:CCS_TEST_MISSING_KEY"); err == nil {
+		t.Error("未设置的环境变量应返回错误")
+	}
+
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("sk-from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Resolve(ctx, "file:"+path); err != nil || got != "sk-from-file" {
+		t.Errorf("Resolve(file) = %q, %v", got, err)
+	}
+
+	// 非引用原样返回
+	if got, err := Resolve(ctx, "sk-plain"); err != nil || got != "sk-plain" {
+		t.Errorf("Resolve(plain) = %q, %v", got, err)
+	}
+}
+
+func TestResolveCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("使用 sh 测试")
+	}
+	ctx := context.Background()
+
+	if got, err := Resolve(ctx, "cmd:echo sk-from-cmd"); err != nil || got != "sk-from-cmd" {
+		t.Errorf("Resolve(cmd) = %q, %v", got, err)
+	}
+	if _, err := Resolve(ctx, "cmd:exit 3"); err == nil {
+		t.Error("命令失败应返回错误")
+	}
+	if _, err := Resolve(ctx, "cmd:true"); err == nil {
+		t.Error("空输出应返回错误")
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	RegisterBackend("test", func(_ context.Context, target string) (string, error) {
+		return "resolved-" + target, nil
+	})
+	defer delete(backends, "test")
+
+	if got, err := Resolve(context.Background(), "test:abc"); err != nil || got != "resolved-abc" {
+		t.Errorf("Resolve(test) = %q, %v", got, err)
+	}
+}
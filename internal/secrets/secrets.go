@@ -0,0 +1,153 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// EncryptedPrefix 加密值前缀，格式: enc:v1:<base64(nonce|ciphertext)>
+	EncryptedPrefix = "enc:v1:"
+	// KDFScrypt 密钥派生算法名称
+	KDFScrypt = "scrypt"
+
+	keyLen  = 32 // AES-256
+	saltLen = 16
+
+	// checkPlaintext 用于校验口令是否正确的已知明文
+	checkPlaintext = "cc-switch-secrets-check"
+)
+
+// 默认 scrypt 参数（交互式场景推荐值）
+const (
+	DefaultScryptN = 1 << 15
+	DefaultScryptR = 8
+	DefaultScryptP = 1
+)
+
+// ErrWrongPassphrase 口令错误
+var ErrWrongPassphrase = errors.New("口令错误")
+
+// Params 密钥派生参数，保存在 config.json 的 secrets 字段中（不包含任何机密信息）
+type Params struct {
+	KDF   string `json:"kdf"`
+	Salt  string `json:"salt"` // base64
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	Check string `json:"check"` // 已知明文的密文，用于校验口令
+}
+
+// Key 派生出的对称密钥
+type Key struct {
+	aead cipher.AEAD
+}
+
+// NewParams 生成新的随机盐和默认参数
+func NewParams() (*Params, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成随机盐失败: %w", err)
+	}
+	return &Params{
+		KDF:  KDFScrypt,
+		Salt: base64.StdEncoding.EncodeToString(salt),
+		N:    DefaultScryptN,
+		R:    DefaultScryptR,
+		P:    DefaultScryptP,
+	}, nil
+}
+
+// DeriveKey 使用口令派生密钥
+func DeriveKey(passphrase string, params *Params) (*Key, error) {
+	if params == nil {
+		return nil, fmt.Errorf("缺少密钥派生参数")
+	}
+	if params.KDF != KDFScrypt {
+		return nil, fmt.Errorf("不支持的密钥派生算法: %s", params.KDF)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("口令不能为空")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("解析盐值失败: %w", err)
+	}
+
+	raw, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{aead: aead}, nil
+}
+
+// Seal 生成口令校验值并写入 params.Check
+func (k *Key) Seal(params *Params) error {
+	check, err := k.Encrypt(checkPlaintext)
+	if err != nil {
+		return err
+	}
+	params.Check = check
+	return nil
+}
+
+// Verify 校验密钥是否与 params 匹配
+func (k *Key) Verify(params *Params) error {
+	plain, err := k.Decrypt(params.Check)
+	if err != nil || plain != checkPlaintext {
+		return ErrWrongPassphrase
+	}
+	return nil
+}
+
+// Encrypt 加密明文，返回带前缀的密文字符串
+func (k *Key) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	sealed := k.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密带前缀的密文字符串
+func (k *Key) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("不是加密值")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("解析密文失败: %w", err)
+	}
+	nonceSize := k.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", fmt.Errorf("密文长度无效")
+	}
+	plain, err := k.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plain), nil
+}
+
+// IsEncrypted 判断值是否为加密值
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
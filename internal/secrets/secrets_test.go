@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	params, err := NewParams()
+	if err != nil {
+		t.Fatalf("NewParams() error = %v", err)
+	}
+	key, err := DeriveKey("correct horse", params)
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	if err := key.Seal(params); err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	encrypted, err := key.Encrypt("sk-secret-token")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Errorf("密文应带有前缀: %s", encrypted)
+	}
+
+	again, _ := key.Encrypt("sk-secret-token")
+	if again == encrypted {
+		t.Error("相同明文两次加密结果不应相同（随机 nonce）")
+	}
+
+	plain, err := key.Decrypt(encrypted)
+	if err != nil || plain != "sk-secret-token" {
+		t.Errorf("Decrypt() = %q, %v", plain, err)
+	}
+
+	// 同一口令重新派生的密钥可以解密
+	key2, _ := DeriveKey("correct horse", params)
+	if err := key2.Verify(params); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	params, _ := NewParams()
+	key, _ := DeriveKey("right", params)
+	key.Seal(params)
+	encrypted, _ := key.Encrypt("sk-x")
+
+	wrong, err := DeriveKey("wrong", params)
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	if err := wrong.Verify(params); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Verify() error = %v, want ErrWrongPassphrase", err)
+	}
+	if _, err := wrong.Decrypt(encrypted); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Decrypt() error = %v, want ErrWrongPassphrase", err)
+	}
+}
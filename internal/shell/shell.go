@@ -0,0 +1,119 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// 支持的 shell
+const (
+	Bash       = "bash"
+	Zsh        = "zsh"
+	Fish       = "fish"
+	PowerShell = "powershell"
+)
+
+// Supported 支持的 shell 列表
+var Supported = []string{Bash, Zsh, Fish, PowerShell}
+
+// Normalize 校验并规范化 shell 名称；为空时自动检测
+func Normalize(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "":
+		return Detect(), nil
+	case Bash, Zsh, Fish, PowerShell:
+		return name, nil
+	case "pwsh", "ps":
+		return PowerShell, nil
+	case "sh":
+		return Bash, nil
+	}
+	return "", fmt.Errorf("不支持的 shell: %s (可选: %s)", name, strings.Join(Supported, ", "))
+}
+
+// Detect 根据 $SHELL 检测当前 shell，无法识别时默认 bash（Windows 默认 powershell）
+func Detect() string {
+	if sh := os.Getenv("SHELL"); sh != "" {
+		switch strings.TrimSuffix(filepath.Base(sh), ".exe") {
+		case Zsh:
+			return Zsh
+		case Fish:
+			return Fish
+		case "pwsh", PowerShell:
+			return PowerShell
+		default:
+			return Bash
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return PowerShell
+	}
+	return Bash
+}
+
+// Export 生成设置环境变量的语句
+func Export(shell, name, value string) string {
+	switch shell {
+	case Fish:
+		return fmt.Sprintf("set -gx %s %s", name, quoteFish(value))
+	case PowerShell:
+		return fmt.Sprintf("$env:%s = %s", name, quotePowerShell(value))
+	default:
+		return fmt.Sprintf("export %s=%s", name, quotePosix(value))
+	}
+}
+
+// Unset 生成清除环境变量的语句
+func Unset(shell, name string) string {
+	switch shell {
+	case Fish:
+		return fmt.Sprintf("set -e %s", name)
+	case PowerShell:
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", name)
+	default:
+		return fmt.Sprintf("unset %s", name)
+	}
+}
+
+// InitScript 返回定义 ccs-use 函数的初始化脚本
+// ccs-use 将 `ccs env` 的输出在当前 shell 中执行，例如: ccs-use work、ccs-use --unset
+func InitScript(shell, executable string) string {
+	switch shell {
+	case Fish:
+		return fmt.Sprintf(`function ccs-use --description 'Activate a cc-switch provider in this shell'
+    %s env $argv --shell fish | source
+end
+`, quoteFish(executable))
+	case PowerShell:
+		return fmt.Sprintf(`function ccs-use {
+    & %s env @args --shell powershell | Out-String | Invoke-Expression
+}
+`, quotePowerShell(executable))
+	default:
+		return fmt.Sprintf(`ccs-use() {
+    eval "$(%s env "$@" --shell %s)"
+}
+`, quotePosix(executable), shell)
+	}
+}
+
+// quotePosix 使用单引号包裹，内部的单引号先结束引用、转义后再重新开始
+func quotePosix(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// quoteFish fish 的单引号字符串中只需转义 \ 和 '
+func quoteFish(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "'", `\'`)
+	return "'" + value + "'"
+}
+
+// quotePowerShell PowerShell 的单引号字符串中用两个单引号表示一个单引号
+func quotePowerShell(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,59 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		shell string
+		value string
+		want  string
+	}{
+		{Bash, "sk-abc", "export KEY='sk-abc'"},
+		{Zsh, "it's", `export KEY='it'\''s'`},
+		{Fish, `a\b'c`, `set -gx KEY 'a\\b\'c'`},
+		{PowerShell, "it's", "$env:KEY = 'it''s'"},
+	}
+	for _, tt := range tests {
+		if got := Export(tt.shell, "KEY", tt.value); got != tt.want {
+			t.Errorf("Export(%s, %q) = %s, want %s", tt.shell, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestUnset(t *testing.T) {
+	if got := Unset(Bash, "KEY"); got != "unset KEY" {
+		t.Errorf("Unset(bash) = %s", got)
+	}
+	if got := Unset(Fish, "KEY"); got != "set -e KEY" {
+		t.Errorf("Unset(fish) = %s", got)
+	}
+	if got := Unset(PowerShell, "KEY"); !strings.HasPrefix(got, "Remove-Item Env:KEY") {
+		t.Errorf("Unset(powershell) = %s", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got, err := Normalize("PWSH"); err != nil || got != PowerShell {
+		t.Errorf("Normalize(PWSH) = %s, %v", got, err)
+	}
+	if _, err := Normalize("tcsh"); err == nil {
+		t.Error("不支持的 shell 应返回错误")
+	}
+
+	t.Setenv("SHELL", "/usr/bin/fish")
+	if got, _ := Normalize(""); got != Fish {
+		t.Errorf("Normalize(\"\") = %s, want fish", got)
+	}
+}
+
+func TestInitScript(t *testing.T) {
+	for _, sh := range Supported {
+		script := InitScript(sh, "ccs")
+		if !strings.Contains(script, "ccs-use") || !strings.Contains(script, "--shell") {
+			t.Errorf("InitScript(%s) 缺少 ccs-use 定义:\n%s", sh, script)
+		}
+	}
+}
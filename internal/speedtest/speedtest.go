@@ -0,0 +1,189 @@
+package speedtest
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+)
+
+const (
+	// DefaultTimeout 单次请求默认超时
+	DefaultTimeout = 5 * time.Second
+	// DefaultCount 每个端点默认测试次数
+	DefaultCount = 3
+	// DefaultConcurrency 默认并发数
+	DefaultConcurrency = 8
+)
+
+// Target 待测速的端点
+type Target struct {
+	Provider string
+	URL      string
+	Custom   bool // 是否为自定义端点（非当前 Base URL）
+}
+
+// Options 测速参数
+type Options struct {
+	Timeout     time.Duration
+	Count       int
+	Concurrency int
+	Client      *http.Client // 为空时使用默认客户端（便于测试注入）
+}
+
+// Result 单个端点的测速结果
+type Result struct {
+	Target
+	Samples   []time.Duration
+	Min       time.Duration
+	Avg       time.Duration
+	P95       time.Duration
+	Status    int // 最后一次响应的 HTTP 状态码
+	Failures  int
+	Attempts  int
+	LastError string
+}
+
+// OK 是否至少有一次成功响应
+func (r Result) OK() bool {
+	return len(r.Samples) > 0
+}
+
+// Run 并发测试所有端点，返回按平均延迟升序排列的结果，全部失败的端点排在最后
+func Run(ctx context.Context, targets []Target, opts Options) []Result {
+	opts = normalizeOptions(opts)
+
+	results := make([]Result, len(targets))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = probe(ctx, target, opts)
+		}(i, target)
+	}
+	wg.Wait()
+
+	sortResults(results)
+	return results
+}
+
+func normalizeOptions(opts Options) Options {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Count <= 0 {
+		opts.Count = DefaultCount
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{
+			// 不跟随重定向，只测量到端点本身的延迟
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	return opts
+}
+
+// probe 对单个端点发起 Count 次请求；任何 HTTP 响应（包括 401/404）都视为可达
+func probe(ctx context.Context, target Target, opts Options) Result {
+	result := Result{Target: target, Attempts: opts.Count}
+
+	for i := 0; i < opts.Count; i++ {
+		latency, status, err := measure(ctx, opts.Client, target.URL, opts.Timeout)
+		if err != nil {
+			result.Failures++
+			result.LastError = err.Error()
+			continue
+		}
+		result.Status = status
+		result.Samples = append(result.Samples, latency)
+	}
+
+	result.Min, result.Avg, result.P95 = summarize(result.Samples)
+	return result
+}
+
+func measure(ctx context.Context, client *http.Client, url string, timeout time.Duration) (time.Duration, int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("User-Agent", "cc-switch-cli-speedtest")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	latency := time.Since(start)
+	resp.Body.Close()
+
+	return latency, resp.StatusCode, nil
+}
+
+// summarize 计算最小值、平均值和 P95（最近秩法）
+func summarize(samples []time.Duration) (min, avg, p95 time.Duration) {
+	if len(samples) == 0 {
+		return 0, 0, 0
+	}
+
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, s := range sorted {
+		total += s
+	}
+
+	idx := (95*len(sorted)+99)/100 - 1
+	if idx < 0 {
+		idx = 0
+	}
+
+	return sorted[0], total / time.Duration(len(sorted)), sorted[idx]
+}
+
+func sortResults(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.OK() != b.OK() {
+			return a.OK()
+		}
+		if a.Failures != b.Failures {
+			return a.Failures < b.Failures
+		}
+		return a.Avg < b.Avg
+	})
+}
+
+// CollectTargets 收集供应商的 Base URL 与全部自定义端点
+func CollectTargets(appName string, providers []config.Provider) []Target {
+	var targets []Target
+	for i := range providers {
+		p := &providers[i]
+		for idx, url := range config.ExtractEndpointsFromProvider(appName, p) {
+			targets = append(targets, Target{
+				Provider: p.Name,
+				URL:      url,
+				Custom:   idx > 0 || config.ExtractAppBaseURLFromProvider(appName, p) == "",
+			})
+		}
+	}
+	return targets
+}
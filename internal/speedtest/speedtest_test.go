@@ -0,0 +1,116 @@
+package speedtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+)
+
+func TestRunRanksByLatency(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer fast.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	// 已关闭的服务器用于模拟不可达端点
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	deadURL := dead.URL
+	dead.Close()
+
+	targets := []Target{
+		{Provider: "dead", URL: deadURL},
+		{Provider: "slow", URL: slow.URL},
+		{Provider: "fast", URL: fast.URL},
+	}
+
+	results := Run(context.Background(), targets, Options{Timeout: time.Second, Count: 2})
+	if len(results) != 3 {
+		t.Fatalf("结果数量 = %d, want 3", len(results))
+	}
+
+	order := []string{results[0].Provider, results[1].Provider, results[2].Provider}
+	want := []string{"fast", "slow", "dead"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("排序 = %v, want %v", order, want)
+		}
+	}
+
+	if results[0].Status != http.StatusUnauthorized {
+		t.Errorf("401 应视为可达, status = %d", results[0].Status)
+	}
+	if results[1].Min < 50*time.Millisecond {
+		t.Errorf("slow min = %v, want >= 50ms", results[1].Min)
+	}
+	if results[2].OK() || results[2].Failures != 2 || results[2].LastError == "" {
+		t.Errorf("dead 结果异常: %+v", results[2])
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	results := Run(context.Background(), []Target{{Provider: "hang", URL: srv.URL}}, Options{Timeout: 20 * time.Millisecond, Count: 1})
+	if results[0].OK() || results[0].Failures != 1 {
+		t.Errorf("超时应计为失败: %+v", results[0])
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var samples []time.Duration
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	min, avg, p95 := summarize(samples)
+	if min != time.Millisecond {
+		t.Errorf("min = %v", min)
+	}
+	if avg != 10500*time.Microsecond {
+		t.Errorf("avg = %v", avg)
+	}
+	if p95 != 19*time.Millisecond {
+		t.Errorf("p95 = %v", p95)
+	}
+}
+
+func TestCollectTargets(t *testing.T) {
+	providers := []config.Provider{
+		{
+			Name: "relay",
+			SettingsConfig: map[string]interface{}{
+				"env": map[string]interface{}{"ANTHROPIC_BASE_URL": "https://a.example.com"},
+			},
+			Meta: &config.ProviderMeta{CustomEndpoints: map[string]config.CustomEndpoint{
+				"https://a.example.com/": {URL: "https://a.example.com/"},
+				"https://b.example.com":  {URL: "https://b.example.com"},
+			}},
+		},
+	}
+
+	targets := CollectTargets("claude", providers)
+	if len(targets) != 2 {
+		t.Fatalf("targets = %+v, want 2 (去重)", targets)
+	}
+	if targets[0].URL != "https://a.example.com" || targets[0].Custom {
+		t.Errorf("第一个应为 Base URL: %+v", targets[0])
+	}
+	if targets[1].URL != "https://b.example.com" || !targets[1].Custom {
+		t.Errorf("第二个应为自定义端点: %+v", targets[1])
+	}
+}
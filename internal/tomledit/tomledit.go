@@ -0,0 +1,764 @@
+// Package tomledit 在保留注释、键顺序和排版的前提下修改 TOML 文档
+//
+// 只重写被修改的键所在的行，文档其余部分保持逐字节不变。
+// 解析是按行进行的，支持表头、点分键、多行数组和多行字符串；
+// 对于无法安全处理的写法，调用方应使用 Matches 校验结果并回退到整体序列化。
+package tomledit
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Document 可编辑的 TOML 文档
+type Document struct {
+	lines           []string
+	crlf            bool
+	trailingNewline bool
+
+	headers []header
+	entries []entry
+}
+
+// header 表头行，例如 [model_providers.relay]
+type header struct {
+	path  []string
+	line  int
+	array bool // [[...]] 数组表
+}
+
+// entry 键值对，可能跨多行（多行数组、多行字符串）
+type entry struct {
+	table      []string // 所在表的路径，根表为空
+	key        []string // 点分键
+	start, end int      // 起止行（含）
+	valueCol   int      // 值在首行中的起始列
+}
+
+func (e entry) fullPath() []string {
+	return joinPath(e.table, e.key)
+}
+
+// Parse 解析 TOML 文档；内容不是合法 TOML 时返回错误
+func Parse(data []byte) (*Document, error) {
+	var probe map[string]interface{}
+	if err := toml.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	text := string(data)
+	d := &Document{
+		crlf:            strings.Contains(text, "\r\n"),
+		trailingNewline: strings.HasSuffix(text, "\n"),
+	}
+	if d.crlf {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	text = strings.TrimSuffix(text, "\n")
+	if text != "" {
+		d.lines = strings.Split(text, "\n")
+	} else {
+		// 空文档新增内容时以换行结尾
+		d.trailingNewline = true
+	}
+	d.reindex()
+	return d, nil
+}
+
+// Bytes 返回编辑后的文档内容
+func (d *Document) Bytes() []byte {
+	if len(d.lines) == 0 {
+		return nil
+	}
+	sep := "\n"
+	if d.crlf {
+		sep = "\r\n"
+	}
+	out := strings.Join(d.lines, sep)
+	if d.trailingNewline {
+		out += sep
+	}
+	return []byte(out)
+}
+
+// Set 将 path 处的值设置为 value
+// value 为表（map）且文档中已存在该表时逐键更新：更新同名键、追加新键、删除多余的键；
+// 值与现有值相同时不改动原文本
+func (d *Document) Set(path []string, value interface{}) {
+	if len(path) == 0 {
+		return
+	}
+
+	if e, ok := d.findEntry(path); ok {
+		if current, err := d.decodeEntry(e); err == nil && equalValues(current, value) {
+			return
+		}
+		d.replaceValue(e, value)
+		return
+	}
+
+	table, isTable := asTable(value)
+	if d.tableExists(path) {
+		if isTable {
+			d.updateTable(path, table)
+			return
+		}
+		// 表被替换为普通值
+		d.Delete(path)
+	}
+
+	d.insert(path, value)
+}
+
+// Delete 删除 path 处的键或表（包括其下的所有子表）
+func (d *Document) Delete(path []string) {
+	if len(path) == 0 {
+		return
+	}
+	for {
+		start, end, ok := d.findRemovable(path)
+		if !ok {
+			return
+		}
+		d.lines = append(d.lines[:start], d.lines[end+1:]...)
+		d.reindex()
+	}
+}
+
+// Matches 判断 data 解析后的内容是否与 want 一致（经过序列化往返以统一数值类型）
+func Matches(data []byte, want map[string]interface{}) bool {
+	var got map[string]interface{}
+	if err := toml.Unmarshal(data, &got); err != nil {
+		return false
+	}
+	wantData, err := toml.Marshal(want)
+	if err != nil {
+		return false
+	}
+	var normalized map[string]interface{}
+	if err := toml.Unmarshal(wantData, &normalized); err != nil {
+		return false
+	}
+	if len(got) == 0 && len(normalized) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(got, normalized)
+}
+
+// ---- 编辑 ----
+
+func (d *Document) updateTable(path []string, table map[string]interface{}) {
+	for _, child := range d.childKeys(path) {
+		if _, keep := table[child]; !keep {
+			d.Delete(joinPath(path, []string{child}))
+		}
+	}
+	for _, key := range sortedKeys(table) {
+		d.Set(joinPath(path, []string{key}), table[key])
+	}
+}
+
+func (d *Document) replaceValue(e entry, value interface{}) {
+	// 保留最后一行值之后的注释
+	last := d.lines[e.end]
+	from := 0
+	if e.end == e.start {
+		from = e.valueCol
+	}
+	suffix := last[valueEnd(last, e.end == e.start, from):]
+	newLine := d.lines[e.start][:e.valueCol] + encodeValue(value) + suffix
+	d.lines = splice(d.lines, e.start, e.end+1, []string{newLine})
+	d.reindex()
+}
+
+func (d *Document) insert(path []string, value interface{}) {
+	parent := path[:len(path)-1]
+	table, isTable := asTable(value)
+
+	// 兄弟项都是独立的表时，新表也作为独立的表追加在它们之后
+	if isTable && (len(parent) == 0 || d.hasSiblingSections(path)) {
+		d.appendSection(path, table)
+		return
+	}
+
+	// 插入到最近的、显式声明的祖先表中
+	container := d.closestHeader(parent)
+	if container == nil && len(parent) > 0 && isTable {
+		d.appendSection(path, table)
+		return
+	}
+
+	var containerPath []string
+	if container != nil {
+		containerPath = container.path
+	}
+	relKey := path[len(containerPath):]
+	line := encodeKey(relKey) + " = " + encodeValue(value)
+	d.insertIntoTable(container, []string{line})
+}
+
+// insertIntoTable 在表的最后一个键之后插入行；表为 nil 时插入根表
+func (d *Document) insertIntoTable(h *header, newLines []string) {
+	var tablePath []string
+	if h != nil {
+		tablePath = h.path
+	}
+
+	pos, hasEntry := -1, false
+	for _, e := range d.entries {
+		if pathEqual(e.table, tablePath) && e.end+1 > pos {
+			pos = e.end + 1
+			hasEntry = true
+		}
+	}
+	if !hasEntry {
+		switch {
+		case h != nil:
+			pos = h.line + 1
+		case len(d.headers) > 0:
+			pos = d.headers[0].line
+		default:
+			pos = len(d.lines)
+		}
+	}
+
+	// 根表原本没有键时，插入在第一个表头之前并用空行隔开
+	if h == nil && !hasEntry && pos < len(d.lines) {
+		newLines = append(newLines, "")
+	}
+	d.lines = splice(d.lines, pos, pos, newLines)
+	d.reindex()
+}
+
+func (d *Document) appendSection(path []string, table map[string]interface{}) {
+	section := []string{"[" + encodeKey(path) + "]"}
+	for _, key := range sortedKeys(table) {
+		section = append(section, encodeKey([]string{key})+" = "+encodeValue(table[key]))
+	}
+
+	// 放在最后一个同级表之后，否则放在文件末尾
+	pos := len(d.lines)
+	parent := path[:len(path)-1]
+	if len(parent) > 0 {
+		for i, h := range d.headers {
+			if hasPrefix(h.path, parent) && len(h.path) > len(parent) {
+				pos = d.sectionContentEnd(i)
+			}
+		}
+	}
+	if pos == len(d.lines) {
+		// 追加到文件末尾时放在结尾空行之前
+		for pos > 0 && strings.TrimSpace(d.lines[pos-1]) == "" {
+			pos--
+		}
+	}
+
+	if pos > 0 && strings.TrimSpace(d.lines[pos-1]) != "" {
+		section = append([]string{""}, section...)
+	}
+	if pos < len(d.lines) && strings.TrimSpace(d.lines[pos]) != "" {
+		section = append(section, "")
+	}
+	d.lines = splice(d.lines, pos, pos, section)
+	d.reindex()
+}
+
+// sectionContentEnd 返回第 i 个表最后一个键之后的行号（不含结尾的空行和注释）
+func (d *Document) sectionContentEnd(i int) int {
+	h := d.headers[i]
+	end := h.line + 1
+	for _, e := range d.entries {
+		if e.start > h.line && (i+1 >= len(d.headers) || e.start < d.headers[i+1].line) {
+			if e.end+1 > end {
+				end = e.end + 1
+			}
+		}
+	}
+	return end
+}
+
+// findRemovable 找到一个属于 path 的键或表，返回需要删除的行范围
+func (d *Document) findRemovable(path []string) (int, int, bool) {
+	for i, h := range d.headers {
+		if !hasPrefix(h.path, path) {
+			continue
+		}
+		start, end := h.line, d.sectionContentEnd(i)-1
+		// 连同之后的空行一起删除，避免留下多余空行
+		for end+1 < len(d.lines) && strings.TrimSpace(d.lines[end+1]) == "" {
+			end++
+		}
+		// 位于文件末尾时改为删除之前的空行
+		if end == len(d.lines)-1 {
+			for start > 0 && strings.TrimSpace(d.lines[start-1]) == "" {
+				start--
+			}
+		}
+		return start, end, true
+	}
+	for _, e := range d.entries {
+		if hasPrefix(e.fullPath(), path) {
+			return e.start, e.end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// ---- 查询 ----
+
+func (d *Document) findEntry(path []string) (entry, bool) {
+	for _, e := range d.entries {
+		if pathEqual(e.fullPath(), path) {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+func (d *Document) decodeEntry(e entry) (interface{}, error) {
+	text := d.lines[e.start][e.valueCol:]
+	if e.end > e.start {
+		text += "\n" + strings.Join(d.lines[e.start+1:e.end+1], "\n")
+	}
+	var m map[string]interface{}
+	if err := toml.Unmarshal([]byte("v = "+text), &m); err != nil {
+		return nil, err
+	}
+	return m["v"], nil
+}
+
+// tableExists 判断文档中是否以表头或点分键的形式定义了 path 表
+func (d *Document) tableExists(path []string) bool {
+	for _, h := range d.headers {
+		if hasPrefix(h.path, path) && !h.array {
+			return true
+		}
+	}
+	for _, e := range d.entries {
+		full := e.fullPath()
+		if len(full) > len(path) && hasPrefix(full, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// childKeys 返回 path 表下直接子键的名称（按出现顺序去重）
+func (d *Document) childKeys(path []string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(full []string) {
+		if len(full) > len(path) && hasPrefix(full, path) && !seen[full[len(path)]] {
+			seen[full[len(path)]] = true
+			keys = append(keys, full[len(path)])
+		}
+	}
+	for _, h := range d.headers {
+		add(h.path)
+	}
+	for _, e := range d.entries {
+		add(e.fullPath())
+	}
+	return keys
+}
+
+func (d *Document) hasSiblingSections(path []string) bool {
+	parent := path[:len(path)-1]
+	for _, h := range d.headers {
+		if len(h.path) == len(path) && hasPrefix(h.path, parent) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Document) closestHeader(path []string) *header {
+	var best *header
+	for i := range d.headers {
+		h := &d.headers[i]
+		if h.array || !hasPrefix(path, h.path) {
+			continue
+		}
+		if best == nil || len(h.path) > len(best.path) {
+			best = h
+		}
+	}
+	return best
+}
+
+// ---- 按行解析 ----
+
+// scanState 跨行的词法状态
+type scanState struct {
+	multiBasic   bool // 位于 """ 字符串中
+	multiLiteral bool // 位于 ''' 字符串中
+	depth        int  // 未闭合的 [ 和 { 数量
+}
+
+func (s scanState) clean() bool {
+	return !s.multiBasic && !s.multiLiteral && s.depth == 0
+}
+
+func (d *Document) reindex() {
+	d.headers = d.headers[:0]
+	d.entries = d.entries[:0]
+
+	var table []string
+	var st scanState
+	current := -1 // 正在扫描的多行条目
+
+	for i, line := range d.lines {
+		if !st.clean() {
+			scanValue(line, 0, &st)
+			if st.clean() && current >= 0 {
+				d.entries[current].end = i
+				current = -1
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			array := strings.HasPrefix(trimmed, "[[")
+			inner := strings.TrimPrefix(trimmed, "[")
+			if array {
+				inner = strings.TrimPrefix(inner, "[")
+			}
+			path, _ := parseKey(inner)
+			table = path
+			d.headers = append(d.headers, header{path: path, line: i, array: array})
+			continue
+		}
+
+		key, rest := parseKey(line)
+		if len(key) == 0 || !strings.HasPrefix(strings.TrimLeft(line[rest:], " \t"), "=") {
+			continue
+		}
+		col := rest + strings.Index(line[rest:], "=") + 1
+		for col < len(line) && (line[col] == ' ' || line[col] == '\t') {
+			col++
+		}
+
+		d.entries = append(d.entries, entry{table: table, key: key, start: i, end: i, valueCol: col})
+		scanValue(line, col, &st)
+		if !st.clean() {
+			current = len(d.entries) - 1
+		}
+	}
+}
+
+// scanValue 扫描一行中的值部分，更新跨行状态，返回注释开始的列（无注释时为行长度）
+func scanValue(line string, from int, st *scanState) int {
+	i := from
+	for i < len(line) {
+		switch {
+		case st.multiBasic:
+			if line[i] == '\\' {
+				i += 2
+				continue
+			}
+			if strings.HasPrefix(line[i:], `"""`) {
+				st.multiBasic = false
+				i += 3
+				for i < len(line) && line[i] == '"' { // 允许 """" 结尾
+					i++
+				}
+				continue
+			}
+		case st.multiLiteral:
+			if strings.HasPrefix(line[i:], "'''") {
+				st.multiLiteral = false
+				i += 3
+				for i < len(line) && line[i] == '\'' {
+					i++
+				}
+				continue
+			}
+		default:
+			c := line[i]
+			switch {
+			case strings.HasPrefix(line[i:], `"""`):
+				st.multiBasic = true
+				i += 3
+				continue
+			case strings.HasPrefix(line[i:], "'''"):
+				st.multiLiteral = true
+				i += 3
+				continue
+			case c == '"':
+				i++
+				for i < len(line) && line[i] != '"' {
+					if line[i] == '\\' {
+						i++
+					}
+					i++
+				}
+			case c == '\'':
+				i++
+				for i < len(line) && line[i] != '\'' {
+					i++
+				}
+			case c == '[' || c == '{':
+				st.depth++
+			case c == ']' || c == '}':
+				st.depth--
+			case c == '#':
+				return i
+			}
+		}
+		i++
+	}
+	return len(line)
+}
+
+// valueEnd 返回值在行中的结束列（去掉结尾的空白和注释）
+func valueEnd(line string, singleLine bool, from int) int {
+	var st scanState
+	if !singleLine {
+		// 多行值的最后一行：跳过字符串闭合前的内容无法可靠判断，仅在无字符串时识别注释
+		if strings.Contains(line, `"""`) || strings.Contains(line, "'''") {
+			return len(strings.TrimRight(line, " \t"))
+		}
+	}
+	end := scanValue(line, from, &st)
+	return len(strings.TrimRight(line[:end], " \t"))
+}
+
+// parseKey 解析点分键，返回各段及键结束的列
+func parseKey(s string) ([]string, int) {
+	var parts []string
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i >= len(s) {
+			return parts, i
+		}
+		switch s[i] {
+		case '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, i
+			}
+			unquoted, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				unquoted = s[i+1 : j]
+			}
+			parts = append(parts, unquoted)
+			i = j + 1
+		case '\'':
+			j := strings.IndexByte(s[i+1:], '\'')
+			if j < 0 {
+				return nil, i
+			}
+			parts = append(parts, s[i+1:i+1+j])
+			i = i + j + 2
+		default:
+			j := i
+			for j < len(s) && isBareKeyChar(s[j]) {
+				j++
+			}
+			if j == i {
+				return parts, i
+			}
+			parts = append(parts, s[i:j])
+			i = j
+		}
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i < len(s) && s[i] == '.' {
+			i++
+			continue
+		}
+		return parts, i
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// ---- 编码 ----
+
+func encodeKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		bare := p != ""
+		for j := 0; j < len(p); j++ {
+			if !isBareKeyChar(p[j]) {
+				bare = false
+				break
+			}
+		}
+		if bare {
+			parts[i] = p
+		} else {
+			parts[i] = encodeString(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func encodeValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return `""`
+	case string:
+		return encodeString(val)
+	case bool:
+		return strconv.FormatBool(val)
+	case int:
+		return strconv.Itoa(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return encodeFloat(val)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case []string:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = encodeString(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = encodeValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		if len(val) == 0 {
+			return "{}"
+		}
+		items := make([]string, 0, len(val))
+		for _, key := range sortedKeys(val) {
+			items = append(items, encodeKey([]string{key})+" = "+encodeValue(val[key]))
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	case map[string]string:
+		m := make(map[string]interface{}, len(val))
+		for k, s := range val {
+			m[k] = s
+		}
+		return encodeValue(m)
+	default:
+		// 其他类型（如 toml.LocalDate）交给 go-toml 编码
+		data, err := toml.Marshal(map[string]interface{}{"v": v})
+		if err != nil {
+			return encodeString(fmt.Sprint(v))
+		}
+		return strings.TrimSpace(strings.TrimPrefix(string(data), "v = "))
+	}
+}
+
+func encodeFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+func encodeString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ---- 工具函数 ----
+
+func asTable(v interface{}) (map[string]interface{}, bool) {
+	m, ok := v.(map[string]interface{})
+	return m, ok
+}
+
+// equalValues 比较现有值与新值（经过序列化往返以统一数值类型）
+func equalValues(current, value interface{}) bool {
+	return Matches([]byte(encodeKey([]string{"v"})+" = "+encodeValue(current)+"\n"), map[string]interface{}{"v": value})
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(a, b []string) []string {
+	out := make([]string, 0, len(a)+len(b))
+	return append(append(out, a...), b...)
+}
+
+func hasPrefix(path, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func pathEqual(a, b []string) bool {
+	return len(a) == len(b) && hasPrefix(a, b)
+}
+
+func splice(lines []string, start, end int, insert []string) []string {
+	out := make([]string, 0, len(lines)-(end-start)+len(insert))
+	out = append(out, lines[:start]...)
+	out = append(out, insert...)
+	return append(out, lines[end:]...)
+}
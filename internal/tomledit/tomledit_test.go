@@ -0,0 +1,181 @@
+package tomledit
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+var update = flag.Bool("update", false, "更新 golden 文件")
+
+// switchTo 模拟切换 Codex 供应商时对 config.toml 的修改
+func switchTo(t *testing.T, doc *Document, providerConfig string) {
+	t.Helper()
+	var ccs map[string]interface{}
+	if err := toml.Unmarshal([]byte(providerConfig), &ccs); err != nil {
+		t.Fatalf("解析供应商配置失败: %v", err)
+	}
+	for _, key := range []string{"model_provider", "model", "model_reasoning_effort", "disable_response_storage"} {
+		if v, ok := ccs[key]; ok {
+			doc.Set([]string{key}, v)
+		}
+	}
+	providers, _ := ccs["model_providers"].(map[string]interface{})
+	for name, v := range providers {
+		doc.Set([]string{"model_providers", name}, v)
+	}
+}
+
+const relayConfig = `model_provider = "relay"
+model = "gpt-5-codex"
+model_reasoning_effort = "high"
+disable_response_storage = true
+
+[model_providers.relay]
+name = "Relay"
+base_url = "https://relay.example.com/v1"
+wire_api = "responses"
+requires_openai_auth = true
+http_headers = { "X-Team" = "infra" }
+`
+
+const otherConfig = `model_provider = "other"
+model = "gpt-5"
+model_reasoning_effort = "low"
+disable_response_storage = true
+
+[model_providers.other]
+name = "Other"
+base_url = "https://other.example.com/v1"
+wire_api = "responses"
+requires_openai_auth = true
+`
+
+func readGolden(t *testing.T, name string, got []byte) []byte {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取 golden 文件失败: %v", err)
+	}
+	return want
+}
+
+func loadInput(t *testing.T) ([]byte, *Document) {
+	t.Helper()
+	input, err := os.ReadFile(filepath.Join("testdata", "codex_config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return input, doc
+}
+
+func TestSwitchGolden(t *testing.T) {
+	_, doc := loadInput(t)
+	switchTo(t, doc, otherConfig)
+
+	got := doc.Bytes()
+	want := readGolden(t, "codex_switch.golden", got)
+	if string(got) != string(want) {
+		t.Errorf("切换结果与 golden 文件不一致:\n%s", got)
+	}
+}
+
+func TestSwitchRoundTripGolden(t *testing.T) {
+	input, doc := loadInput(t)
+	switchTo(t, doc, otherConfig)
+	switchTo(t, doc, relayConfig)
+
+	got := doc.Bytes()
+	want := readGolden(t, "codex_roundtrip.golden", got)
+	if string(got) != string(want) {
+		t.Errorf("往返切换结果与 golden 文件不一致:\n%s", got)
+	}
+
+	// 往返后只应多出新增的供应商段，原文件内容逐行保留
+	if !strings.HasPrefix(string(got), string(input[:strings.Index(string(input), "[mcp_servers.filesystem]")])) {
+		t.Errorf("往返切换改变了未受管的内容:\n%s", got)
+	}
+}
+
+func TestSetUnchangedValueKeepsText(t *testing.T) {
+	input, doc := loadInput(t)
+	switchTo(t, doc, relayConfig)
+	if got := doc.Bytes(); string(got) != string(input) {
+		t.Errorf("值未变化时文件应逐字节保持不变:\n%s", got)
+	}
+}
+
+func TestSetReplacesMultiLineValueAndKeepsComment(t *testing.T) {
+	doc, err := Parse([]byte("a = [\n  1,\n  2,\n] # keep\nb = 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Set([]string{"a"}, []interface{}{int64(3)})
+	doc.Set([]string{"c"}, "new")
+	want := "a = [3] # keep\nb = 1\nc = \"new\"\n"
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDeleteTableWithSubtables(t *testing.T) {
+	_, doc := loadInput(t)
+	doc.Delete([]string{"mcp_servers", "filesystem"})
+
+	got := string(doc.Bytes())
+	if strings.Contains(got, "mcp_servers") || strings.Contains(got, "DEBUG") {
+		t.Errorf("MCP 服务器未删除:\n%s", got)
+	}
+	if !strings.Contains(got, "\n\n[profiles.fast]\nmodel = \"gpt-5-mini\"\n") {
+		t.Errorf("相邻的表不应受影响:\n%s", got)
+	}
+}
+
+func TestSetNewTableIntoEmptyDocument(t *testing.T) {
+	doc, err := Parse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Set([]string{"mcp_servers", "fs"}, map[string]interface{}{
+		"command": "npx",
+		"env":     map[string]interface{}{"A": "1"},
+	})
+	want := "[mcp_servers.fs]\ncommand = \"npx\"\nenv = { A = \"1\" }\n"
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPreservesCRLF(t *testing.T) {
+	doc, err := Parse([]byte("# c\r\nmodel = \"a\"\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Set([]string{"model"}, "b")
+	if got := string(doc.Bytes()); got != "# c\r\nmodel = \"b\"\r\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	if !Matches([]byte("a = 1\n[t]\nb = \"x\"\n"), map[string]interface{}{"a": int64(1), "t": map[string]interface{}{"b": "x"}}) {
+		t.Error("内容一致时应返回 true")
+	}
+	if Matches([]byte("a = 2\n"), map[string]interface{}{"a": int64(1)}) {
+		t.Error("内容不一致时应返回 false")
+	}
+}
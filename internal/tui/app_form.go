@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"errors"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/i18n"
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// customApp 返回当前的自定义应用，内置应用返回 nil
+// 自定义应用的表单由定义中的字段生成：[配置名称, 字段1, 字段2, ...]
+func (m Model) customApp() config.AppAdapter {
+	if config.IsBuiltinApp(m.currentApp) {
+		return nil
+	}
+	app, err := m.manager.App(m.currentApp)
+	if err != nil {
+		return nil
+	}
+	return app
+}
+
+// customFormLabels 自定义应用的表单标签
+func customFormLabels(app config.AppAdapter) []string {
+	labels := []string{"配置名称"}
+	for _, f := range app.FormFields() {
+		labels = append(labels, f.Label)
+	}
+	return labels
+}
+
+// initCustomForm 按自定义应用的字段初始化表单
+func (m *Model) initCustomForm(app config.AppAdapter, provider *config.Provider) {
+	fields := app.FormFields()
+	m.inputs = make([]textinput.Model, len(fields)+1)
+	m.focusIndex = 0
+	m.modelSelectorActive = false
+	m.modelSelectorCursor = 0
+	m.apiTokenVisible = false
+
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "配置名称"
+	m.inputs[0].Focus()
+	m.inputs[0].CharLimit = 50
+	m.inputs[0].Width = 55
+
+	for i, f := range fields {
+		input := textinput.New()
+		input.Placeholder = f.Key
+		input.CharLimit = 500
+		input.Width = 55
+		m.inputs[i+1] = input
+	}
+
+	source := provider
+	if source == nil {
+		source = m.copyFromProvider
+	}
+	if source != nil {
+		if provider != nil {
+			m.inputs[0].SetValue(provider.Name)
+		}
+		envMap, _ := source.SettingsConfig["env"].(map[string]interface{})
+		for i, f := range fields {
+			if val, ok := envMap[f.Key].(string); ok {
+				m.inputs[i+1].SetValue(val)
+			}
+		}
+	}
+	m.copyFromProvider = nil
+	m.applyTokenVisibility()
+}
+
+// applyCustomTokenVisibility 切换自定义应用敏感字段的显示状态
+func (m *Model) applyCustomTokenVisibility(app config.AppAdapter) {
+	for i, f := range app.FormFields() {
+		if !f.Secret || i+1 >= len(m.inputs) {
+			continue
+		}
+		if m.apiTokenVisible {
+			m.inputs[i+1].EchoMode = textinput.EchoNormal
+		} else {
+			m.inputs[i+1].EchoMode = textinput.EchoPassword
+		}
+	}
+}
+
+// submitCustomForm 保存自定义应用的供应商
+func (m *Model) submitCustomForm(app config.AppAdapter) {
+	name := m.inputs[0].Value()
+	if name == "" {
+		m.err = errors.New(i18n.T("error.name_required"))
+		return
+	}
+
+	values := make(map[string]string)
+	for i, f := range app.FormFields() {
+		if i+1 < len(m.inputs) {
+			values[f.Key] = m.inputs[i+1].Value()
+		}
+	}
+
+	var err error
+	if m.mode == "edit" {
+		err = m.manager.UpdateAppProvider(m.currentApp, m.editName, name, "", values)
+	} else {
+		err = m.manager.AddAppProvider(m.currentApp, name, "", values)
+	}
+	if err != nil {
+		m.err = err
+		m.message = ""
+		return
+	}
+
+	if m.mode == "edit" {
+		m.message = i18n.T("success.provider_updated")
+	} else {
+		m.message = i18n.T("success.provider_added")
+	}
+	m.err = nil
+	m.mode = "list"
+	m.refreshProviders()
+	m.syncModTime()
+}
@@ -17,22 +17,17 @@ func (m Model) handleAppSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.appCursor > 0 {
 			m.appCursor--
 		} else {
-			m.appCursor = 2 // 循环到 Gemini
+			m.appCursor = len(m.manager.AppNames()) - 1 // 循环到最后一个应用
 		}
 	case "down", "j":
-		if m.appCursor < 2 { // 现在有 3 个选项：Claude(0), Codex(1), Gemini(2)
+		if m.appCursor < len(m.manager.AppNames())-1 {
 			m.appCursor++
 		} else {
 			m.appCursor = 0 // 循环到 Claude
 		}
 	case "enter":
-		switch m.appCursor {
-		case 0:
-			m.currentApp = "claude"
-		case 1:
-			m.currentApp = "codex"
-		case 2:
-			m.currentApp = "gemini"
+		if apps := m.manager.AppNames(); m.appCursor < len(apps) {
+			m.currentApp = apps[m.appCursor]
 		}
 		m.cursor = 0
 		m.refreshProviders()
@@ -53,8 +48,7 @@ func (m Model) viewAppSelect() string {
 		Render(fmt.Sprintf("选择应用 (v%s)", m.getVersion()))
 	s.WriteString(title + "\n\n")
 
-	apps := []string{"Claude Code", "Codex CLI", "Gemini CLI"}
-	for i, app := range apps {
+	for i, app := range m.manager.AppNames() {
 		marker := "○"
 		style := lipgloss.NewStyle().Padding(0, 1)
 
@@ -66,7 +60,7 @@ func (m Model) viewAppSelect() string {
 				Bold(true)
 		}
 
-		line := fmt.Sprintf("%s %s", marker, style.Render(app))
+		line := fmt.Sprintf("%s %s", marker, style.Render(m.manager.AppDisplayName(app)))
 		s.WriteString(line + "\n")
 	}
 
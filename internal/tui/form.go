@@ -59,9 +59,9 @@ var (
 		{Label: "gemini-3-pro-preview", Value: "gemini-3-pro-preview"},
 	}
 
-	codexConfigBaseURLRegex   = regexp.MustCompile(`base_url\s*=\s*"([^"]+)"`)
-	codexConfigModelRegex     = regexp.MustCompile(`model\s*=\s*"([^"]+)"`)
-	codexConfigReasoningRegex = regexp.MustCompile(`model_reasoning_effort\s*=\s*"([^"]+)"`)
+	codexConfigBaseURLRegex   = regexp.MustCompile(`base_url\s*=\s*["']([^"']+)["']`)
+	codexConfigModelRegex     = regexp.MustCompile(`model\s*=\s*["']([^"']+)["']`)
+	codexConfigReasoningRegex = regexp.MustCompile(`model_reasoning_effort\s*=\s*["']([^"']+)["']`)
 )
 
 // handleFormKeys handles keys in add/edit mode
@@ -290,6 +290,10 @@ func (m Model) isReadOnlyField(index int) bool {
 }
 
 func (m *Model) applyTokenVisibility() {
+	if app := m.customApp(); app != nil {
+		m.applyCustomTokenVisibility(app)
+		return
+	}
 	if len(m.inputs) <= 1 {
 		return
 	}
@@ -301,6 +305,11 @@ func (m *Model) applyTokenVisibility() {
 }
 
 func (m *Model) submitForm() {
+	if app := m.customApp(); app != nil {
+		m.submitCustomForm(app)
+		return
+	}
+
 	name := m.inputs[0].Value()
 
 	// Gemini 特殊处理：字段映射为 [Name, API Key, Base URL, Model]
@@ -452,13 +461,7 @@ func (m Model) viewForm() string {
 		Padding(0, 1)
 
 	// 获取当前模块名称
-	appName := "Claude Code"
-	switch m.currentApp {
-	case "codex":
-		appName = "Codex CLI"
-	case "gemini":
-		appName = "Gemini CLI"
-	}
+	appName := m.manager.AppDisplayName(m.currentApp)
 
 	if m.mode == "add" {
 		s.WriteString(title.Render(fmt.Sprintf("添加新配置 - %s (v%s)", appName, m.getVersion())) + "\n\n")
@@ -602,6 +605,9 @@ func (m Model) viewForm() string {
 }
 
 func (m Model) formLabels() []string {
+	if app := m.customApp(); app != nil {
+		return customFormLabels(app)
+	}
 	if m.currentApp == "gemini" {
 		return []string{"配置名称", "GEMINI_API_KEY", "GOOGLE_GEMINI_BASE_URL", "GEMINI_MODEL"}
 	}
@@ -624,6 +630,11 @@ func (m Model) isCodexReasoningFieldVisible() bool {
 }
 
 func (m *Model) initForm(provider *config.Provider) {
+	if app := m.customApp(); app != nil {
+		m.initCustomForm(app, provider)
+		return
+	}
+
 	fieldCount := 5
 	switch m.currentApp {
 	case "claude":
@@ -961,12 +972,12 @@ func (m *Model) loadLiveConfigForForm() (token, baseURL, primaryModel, haikuMode
 		return "", "", "", "", "", "", false
 	}
 
-	token = liveSettings.Env.AnthropicAuthToken
-	baseURL = liveSettings.Env.AnthropicBaseURL
-	primaryModel = liveSettings.Env.AnthropicModel
-	haikuModel = liveSettings.Env.AnthropicDefaultHaikuModel
-	sonnetModel = liveSettings.Env.AnthropicDefaultSonnetModel
-	opusModel = liveSettings.Env.AnthropicDefaultOpusModel
+	token = liveSettings.Env.Get("ANTHROPIC_AUTH_TOKEN")
+	baseURL = liveSettings.Env.Get("ANTHROPIC_BASE_URL")
+	primaryModel = liveSettings.Env.Get("ANTHROPIC_MODEL")
+	haikuModel = liveSettings.Env.Get("ANTHROPIC_DEFAULT_HAIKU_MODEL")
+	sonnetModel = liveSettings.Env.Get("ANTHROPIC_DEFAULT_SONNET_MODEL")
+	opusModel = liveSettings.Env.Get("ANTHROPIC_DEFAULT_OPUS_MODEL")
 
 	if token != "" || baseURL != "" || primaryModel != "" || haikuModel != "" || sonnetModel != "" || opusModel != "" {
 		return token, baseURL, primaryModel, haikuModel, sonnetModel, opusModel, true
@@ -1,9 +1,10 @@
 package tui
 
 import (
-	"os"
+	"fmt"
 	"unicode"
 
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/template"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/version"
 )
@@ -23,6 +24,39 @@ func displayWidth(s string) int {
 	return width
 }
 
+// projectPinMismatch 返回项目固定的配置名称；未固定或与当前配置一致时返回空字符串
+func (m Model) projectPinMismatch(appName string) string {
+	pinned := m.projectPins.Get(appName)
+	if pinned == "" {
+		return ""
+	}
+	if current := m.manager.GetCurrentProviderForApp(appName); current != nil && current.Name == pinned {
+		return ""
+	}
+	return pinned
+}
+
+// nextClaudeScope 循环切换 Claude 设置写入范围
+func (m *Model) nextClaudeScope() {
+	for i, scope := range config.ClaudeScopes {
+		if scope == m.claudeScope {
+			m.claudeScope = config.ClaudeScopes[(i+1)%len(config.ClaudeScopes)]
+			break
+		}
+	}
+	path, _ := m.manager.GetClaudeSettingsPathForScope(m.claudeScope)
+	m.message = fmt.Sprintf("Claude 写入范围: %s (%s)", m.claudeScope, path)
+	m.err = nil
+}
+
+// switchProvider 切换配置，Claude 按当前写入范围写入设置文件
+func (m Model) switchProvider(appName, name string) error {
+	if appName == "claude" {
+		return m.manager.SwitchClaudeProviderToScope(name, m.claudeScope)
+	}
+	return m.manager.SwitchProviderForApp(appName, name)
+}
+
 // getVersion 获取版本号
 func (m Model) getVersion() string {
 	return version.GetVersion()
@@ -84,6 +118,7 @@ func (m *Model) syncColumnCursors() {
 // saveViewModePreference 静默保存视图模式偏好
 func (m *Model) saveViewModePreference() {
 	_ = m.manager.SetViewMode(m.viewMode)
+	m.syncModTime()
 }
 
 // refreshTemplates 刷新模板列表
@@ -124,9 +159,32 @@ func (m Model) templateCategoryDisplay(category string) string {
 	}
 }
 
-// syncModTime 更新配置文件修改时间缓存
+// syncModTime 自身写入后更新文件指纹缓存，避免被误判为外部修改
 func (m *Model) syncModTime() {
-	if info, err := os.Stat(m.configPath); err == nil {
-		m.lastModTime = info.ModTime()
+	m.watchSnapshot = config.TakeSnapshot(m.manager.WatchedPaths(), m.watchSnapshot)
+}
+
+// mcpAppTag MCP 列表中的应用标签，内置应用使用单字母缩写
+func mcpAppTag(app string) string {
+	switch app {
+	case "claude":
+		return "[C]"
+	case "codex":
+		return "[X]"
+	case "gemini":
+		return "[G]"
+	default:
+		return "[" + app + "]"
+	}
+}
+
+// nextApp 返回 t 键切换到的下一个应用
+func (m Model) nextApp() string {
+	apps := m.manager.AppNames()
+	for i, app := range apps {
+		if app == m.currentApp {
+			return apps[(i+1)%len(apps)]
+		}
 	}
+	return "claude"
 }
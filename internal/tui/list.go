@@ -8,6 +8,7 @@ import (
 	"unicode"
 
 	"github.com/YangQing-Lin/cc-switch-cli/internal/backup"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/i18n"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -32,6 +33,9 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.message = "切换到三列视图"
 		m.err = nil
 		return m, nil
+	case "s":
+		m.nextClaudeScope()
+		return m, nil
 	case "up", "k":
 		if len(m.providers) > 0 {
 			if m.cursor > 0 {
@@ -119,12 +123,14 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			isSwitch := current == nil || provider.ID != current.ID
 
 			// 无论是否已激活，都执行切换操作（如果已激活则是覆盖）
-			err := m.manager.SwitchProviderForApp(m.currentApp, provider.Name)
+			err := m.switchProvider(m.currentApp, provider.Name)
 			if err != nil {
 				m.err = err
 				m.message = ""
 			} else {
-				if isSwitch {
+				if m.currentApp == "claude" && m.claudeScope != config.ClaudeScopeGlobal {
+					m.message = fmt.Sprintf("已写入 %s 范围设置: %s", m.claudeScope, provider.Name)
+				} else if isSwitch {
 					m.message = i18n.T("success.switched_to") + ": " + provider.Name
 				} else {
 					m.message = "✓ 已覆盖 live 配置: " + provider.Name
@@ -176,17 +182,8 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.err = nil
 		return m, tea.ClearScreen
 	case "t":
-		// Toggle between Claude, Codex and Gemini
-		switch m.currentApp {
-		case "claude":
-			m.currentApp = "codex"
-		case "codex":
-			m.currentApp = "gemini"
-		case "gemini":
-			m.currentApp = "claude"
-		default:
-			m.currentApp = "claude"
-		}
+		// 按注册顺序循环切换应用（内置应用在前，之后是自定义应用）
+		m.currentApp = m.nextApp()
 		m.cursor = 0
 		m.refreshProviders()
 		m.message = fmt.Sprintf("切换到 %s", m.currentApp)
@@ -327,6 +324,10 @@ func (m Model) handleMultiColumnKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.err = nil
 		return m, nil
 
+	case "s":
+		m.nextClaudeScope()
+		return m, nil
+
 	case "tab":
 		// Tab 切换到下一列
 		if m.columnCursor < 2 {
@@ -398,14 +399,19 @@ func (m Model) handleMultiColumnKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.columnProviders[col]) > 0 {
 			provider := m.columnProviders[col][m.columnCursors[col]]
 			appName := m.columnToAppName(col)
-			err := m.manager.SwitchProviderForApp(appName, provider.Name)
+			err := m.switchProvider(appName, provider.Name)
 			if err != nil {
 				m.err = err
 				m.message = ""
 			} else {
-				m.message = fmt.Sprintf("已切换 %s 配置: %s", appName, provider.Name)
+				if appName == "claude" && m.claudeScope != config.ClaudeScopeGlobal {
+					m.message = fmt.Sprintf("已写入 %s 范围设置: %s", m.claudeScope, provider.Name)
+				} else {
+					m.message = fmt.Sprintf("已切换 %s 配置: %s", appName, provider.Name)
+				}
 				m.err = nil
 				m.refreshAllColumns()
+				m.syncModTime()
 			}
 		}
 
@@ -641,19 +647,16 @@ func (m Model) viewList() string {
 	var s strings.Builder
 
 	// Title with current app indicator and version
-	appName := "Claude Code"
-	switch m.currentApp {
-	case "codex":
-		appName = "Codex CLI"
-	case "gemini":
-		appName = "Gemini CLI"
-	}
+	appName := m.manager.AppDisplayName(m.currentApp)
 
 	// 添加便携模式标识
 	portableIndicator := ""
 	if m.isPortableMode {
 		portableIndicator = " (便携版)"
 	}
+	if m.currentApp == "claude" && m.claudeScope != config.ClaudeScopeGlobal {
+		portableIndicator += fmt.Sprintf(" [写入范围: %s]", m.claudeScope)
+	}
 
 	title := lipgloss.NewStyle().
 		Bold(true).
@@ -662,6 +665,12 @@ func (m Model) viewList() string {
 		Render(fmt.Sprintf("CC Switch CLI v%s - %s 配置管理%s", m.getVersion(), appName, portableIndicator))
 	s.WriteString(title + "\n\n")
 
+	// 当前配置与项目 .ccs.toml 固定的配置不一致时提示
+	if pinned := m.projectPinMismatch(m.currentApp); pinned != "" {
+		pinStyle := lipgloss.NewStyle().Foreground(warningColor)
+		s.WriteString(pinStyle.Render(fmt.Sprintf("⚠ 项目固定配置为 %s，与当前配置不一致 (ccs apply)", pinned)) + "\n\n")
+	}
+
 	// Status message
 	if m.err != nil {
 		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF3B30")).Bold(true)
@@ -745,6 +754,7 @@ func (m Model) viewList() string {
 		"x: Codex",
 		"g: Gemini",
 		"v: 三列视图",
+		"s: Claude 写入范围",
 		"p: 便携模式",
 		"u: 检查更新",
 		"U: 执行更新",
@@ -775,6 +785,9 @@ func (m Model) viewListMulti() string {
 	if m.isPortableMode {
 		portableIndicator = " (便携版)"
 	}
+	if m.claudeScope != config.ClaudeScopeGlobal {
+		portableIndicator += fmt.Sprintf(" [Claude 写入范围: %s]", m.claudeScope)
+	}
 
 	// 标题
 	title := lipgloss.NewStyle().
@@ -783,6 +796,19 @@ func (m Model) viewListMulti() string {
 		Render(fmt.Sprintf("CC Switch CLI v%s - 三列视图%s", m.getVersion(), portableIndicator))
 	s.WriteString(title + "\n\n")
 
+	// 当前配置与项目 .ccs.toml 固定的配置不一致时提示
+	var pinMismatches []string
+	for i := 0; i < 3; i++ {
+		appName := m.columnToAppName(i)
+		if pinned := m.projectPinMismatch(appName); pinned != "" {
+			pinMismatches = append(pinMismatches, fmt.Sprintf("%s=%s", appName, pinned))
+		}
+	}
+	if len(pinMismatches) > 0 {
+		pinStyle := lipgloss.NewStyle().Foreground(warningColor)
+		s.WriteString(pinStyle.Render("⚠ 与项目固定配置不一致: "+strings.Join(pinMismatches, ", ")+" (ccs apply)") + "\n\n")
+	}
+
 	// 状态消息
 	if m.err != nil {
 		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF3B30")).Bold(true)
@@ -865,6 +891,7 @@ func (m Model) viewListMulti() string {
 		"l: 备份列表",
 		"m: 模板管理",
 		"M: MCP管理",
+		"s: Claude 写入范围",
 		"p: 便携模式",
 		"u: 检查更新",
 		"U: 执行更新",
@@ -63,14 +63,10 @@ func (m Model) viewMcpList() string {
 
 			// 应用标签
 			var appTags []string
-			if server.Apps.Claude {
-				appTags = append(appTags, "[C]")
-			}
-			if server.Apps.Codex {
-				appTags = append(appTags, "[X]")
-			}
-			if server.Apps.Gemini {
-				appTags = append(appTags, "[G]")
+			for _, app := range m.manager.McpAppNames() {
+				if server.Apps[app] {
+					appTags = append(appTags, mcpAppTag(app))
+				}
 			}
 			appsText := strings.Join(appTags, " ")
 			if len(appTags) == 0 {
@@ -137,7 +133,7 @@ func (m Model) handleMcpListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.mcpServers) > 0 {
 			// 进入应用多选模式
 			m.selectedMcp = &m.mcpServers[m.mcpCursor]
-			m.mcpAppsToggle = m.selectedMcp.Apps
+			m.mcpAppsToggle = m.selectedMcp.Apps.Clone()
 			m.mcpAppsCursor = 0
 			m.mcpMode = "apps_toggle"
 			m.message = ""
@@ -175,7 +171,10 @@ func (m Model) handleMcpListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.err = nil
 	case "s":
 		// 同步所有 MCP 服务器
-		if err := m.manager.SyncAllMcpServers(); err != nil {
+		finish := m.manager.BeginOperation("同步所有 MCP 服务器")
+		err := m.manager.SyncAllMcpServers()
+		finish()
+		if err != nil {
 			m.err = fmt.Errorf("同步失败: %w", err)
 			m.message = ""
 		} else {
@@ -437,10 +436,12 @@ func (m *Model) saveMcpForm() error {
 
 	// 添加或更新
 	if m.mcpMode == "add" {
+		defer m.manager.BeginOperation(fmt.Sprintf("添加 MCP 服务器 '%s'", server.ID))()
 		if err := m.manager.AddMcpServer(server); err != nil {
 			return err
 		}
 	} else {
+		defer m.manager.BeginOperation(fmt.Sprintf("更新 MCP 服务器 '%s'", server.ID))()
 		if err := m.manager.UpdateMcpServer(server); err != nil {
 			return err
 		}
@@ -554,6 +555,7 @@ func (m Model) handleMcpDeleteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "y", "Y":
 		if m.selectedMcp != nil {
+			defer m.manager.BeginOperation(fmt.Sprintf("删除 MCP 服务器 '%s'", m.selectedMcp.ID))()
 			// 删除服务器
 			if err := m.manager.DeleteMcpServer(m.selectedMcp.ID); err != nil {
 				m.err = fmt.Errorf("删除失败: %w", err)
@@ -618,21 +620,12 @@ func (m Model) viewMcpAppsToggle() string {
 	if m.selectedMcp != nil {
 		s.WriteString(fmt.Sprintf("为 MCP 服务器 '%s' 选择要启用的应用：\n\n", m.selectedMcp.Name))
 
-		apps := []struct {
-			name    string
-			enabled *bool
-		}{
-			{"Claude Code", &m.mcpAppsToggle.Claude},
-			{"Codex CLI", &m.mcpAppsToggle.Codex},
-			{"Gemini CLI", &m.mcpAppsToggle.Gemini},
-		}
-
-		for i, app := range apps {
+		for i, app := range m.manager.McpAppNames() {
 			isCursor := i == m.mcpAppsCursor
 
 			marker := "○"
 			checkbox := "[ ]"
-			if *app.enabled {
+			if m.mcpAppsToggle[app] {
 				checkbox = "[✓]"
 			}
 
@@ -648,7 +641,7 @@ func (m Model) viewMcpAppsToggle() string {
 				style = style.Padding(0, 1)
 			}
 
-			line := fmt.Sprintf("%s %s %s", marker, checkbox, style.Render(app.name))
+			line := fmt.Sprintf("%s %s %s", marker, checkbox, style.Render(m.manager.AppDisplayName(app)))
 			s.WriteString(line + "\n")
 		}
 
@@ -672,26 +665,23 @@ func (m Model) handleMcpAppsToggleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.mcpAppsCursor > 0 {
 			m.mcpAppsCursor--
 		} else {
-			m.mcpAppsCursor = 2
+			m.mcpAppsCursor = len(m.manager.McpAppNames()) - 1
 		}
 	case "down", "j":
-		if m.mcpAppsCursor < 2 {
+		if m.mcpAppsCursor < len(m.manager.McpAppNames())-1 {
 			m.mcpAppsCursor++
 		} else {
 			m.mcpAppsCursor = 0
 		}
 	case " ":
 		// 切换当前选项
-		switch m.mcpAppsCursor {
-		case 0:
-			m.mcpAppsToggle.Claude = !m.mcpAppsToggle.Claude
-		case 1:
-			m.mcpAppsToggle.Codex = !m.mcpAppsToggle.Codex
-		case 2:
-			m.mcpAppsToggle.Gemini = !m.mcpAppsToggle.Gemini
+		if apps := m.manager.McpAppNames(); m.mcpAppsCursor < len(apps) {
+			app := apps[m.mcpAppsCursor]
+			m.mcpAppsToggle[app] = !m.mcpAppsToggle[app]
 		}
 	case "enter":
 		if m.selectedMcp != nil {
+			defer m.manager.BeginOperation(fmt.Sprintf("更新 MCP 服务器 '%s' 的启用应用", m.selectedMcp.ID))()
 			// 检测是否为新服务器
 			existingServer, err := m.manager.GetMcpServer(m.selectedMcp.ID)
 			if err != nil {
@@ -838,7 +828,7 @@ func (m Model) handleMcpPresetKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// 选择预设，进入应用选择
 			preset := m.mcpPresets[m.mcpPresetCursor]
 			m.selectedMcp = &preset
-			m.mcpAppsToggle = config.McpApps{Claude: false, Codex: false, Gemini: false}
+			m.mcpAppsToggle = config.McpApps{}
 			m.mcpAppsCursor = 0
 			m.mcpMode = "apps_toggle"
 			m.message = ""
@@ -88,7 +88,9 @@ func (m Model) handleDiffPreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.message = ""
 		m.err = nil
 	case "y", "Y":
+		finish := m.manager.BeginOperation(fmt.Sprintf("应用模板 '%s' 到 %s", m.selectedTemplate.Name, m.selectedTargetPath), m.selectedTargetPath)
 		err := m.templateManager.ApplyTemplate(m.selectedTemplate.ID, m.selectedTargetPath)
+		finish()
 		if err != nil {
 			m.err = fmt.Errorf("应用模板失败: %w", err)
 			m.message = ""
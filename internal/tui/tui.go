@@ -9,6 +9,7 @@ import (
 	"github.com/YangQing-Lin/cc-switch-cli/internal/backup"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/portable"
+	"github.com/YangQing-Lin/cc-switch-cli/internal/project"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/template"
 	"github.com/YangQing-Lin/cc-switch-cli/internal/version"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -30,8 +31,9 @@ type Model struct {
 	inputs              []textinput.Model
 	focusIndex          int
 	currentApp          string              // "claude" or "codex" or "gemini"
-	appCursor           int                 // 应用选择光标 (0=Claude, 1=Codex, 2=Gemini)
-	lastModTime         time.Time           // 配置文件最后修改时间
+	appCursor           int                 // 应用选择光标（按 AppNames 顺序）
+	watchSnapshot       config.FileSnapshot // config.json 与 live 文件的指纹，用于检测外部修改
+	reloadConflict      []string            // 编辑期间被外部修改的文件，非空时显示冲突提示
 	configPath          string              // 配置文件路径
 	backupList          []backup.BackupInfo // 备份列表
 	backupCursor        int                 // 备份列表光标
@@ -76,6 +78,12 @@ type Model struct {
 	// API Token 显示状态
 	apiTokenVisible bool
 
+	// 项目固定配置（启动时从当前目录查找 .ccs.toml）
+	projectPins *project.File
+
+	// Claude 设置写入范围: "global", "project", "local"
+	claudeScope string
+
 	// 三列视图模式相关
 	viewMode        string               // "single" 或 "multi" (三列模式)
 	columnCursor    int                  // 当前聚焦的列索引 (0=Claude, 1=Codex, 2=Gemini)
@@ -115,10 +123,6 @@ type updateDownloadMsg struct {
 // New 创建新的 TUI 模型
 func New(manager *config.Manager) Model {
 	configPath := manager.GetConfigPath()
-	var modTime time.Time
-	if info, err := os.Stat(configPath); err == nil {
-		modTime = info.ModTime()
-	}
 
 	// 初始化模板管理器
 	homeDir, _ := os.UserHomeDir()
@@ -135,14 +139,17 @@ func New(manager *config.Manager) Model {
 		currentApp:      "claude",
 		appCursor:       0,
 		configPath:      configPath,
-		lastModTime:     modTime,
+		watchSnapshot:   config.TakeSnapshot(manager.WatchedPaths(), nil),
 		templateManager: templateManager,
 		isPortableMode:  portable.IsPortableMode(),
 		viewMode:        manager.GetViewMode(), // 从配置加载视图模式
+		claudeScope:     config.ClaudeScopeGlobal,
 	}
 	if initErr != nil {
 		m.err = initErr
 	}
+	// 项目文件解析失败不影响 TUI 使用，仅不显示固定提示
+	m.projectPins, _ = project.Discover()
 	m.refreshProviders()
 
 	// 如果是三列模式，初始化所有列的配置缓存
@@ -195,7 +202,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 	case tickMsg:
-		// tick 用于其他周期性任务（如有），配置单向流出，不检查外部变化
+		// 检查 GUI 或其他 ccs 进程对配置的修改
+		m.checkExternalChanges()
 		return m, tickCmd()
 
 	case updateCheckMsg:
@@ -224,6 +232,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
+		if len(m.reloadConflict) > 0 {
+			return m.handleReloadConflictKeys(msg)
+		}
 		switch m.mode {
 		case "list":
 			return m.handleListKeys(msg)
@@ -282,6 +293,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	if len(m.reloadConflict) > 0 {
+		return m.viewReloadConflict()
+	}
 	switch m.mode {
 	case "list":
 		if m.viewMode == "multi" {
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// checkExternalChanges 比较文件指纹，空闲时自动重新加载，编辑中则转为冲突提示
+func (m *Model) checkExternalChanges() {
+	if len(m.reloadConflict) > 0 {
+		// 等待用户处理当前冲突
+		return
+	}
+	next := config.TakeSnapshot(m.manager.WatchedPaths(), m.watchSnapshot)
+	changed := m.watchSnapshot.Changed(next)
+	m.watchSnapshot = next
+	if len(changed) == 0 {
+		return
+	}
+	if m.isEditing() {
+		m.reloadConflict = changed
+		return
+	}
+	m.reloadFromDisk(changed)
+}
+
+// isEditing 当前是否有未保存的编辑（配置表单、MCP 表单或 MCP 应用多选）
+func (m Model) isEditing() bool {
+	switch m.mode {
+	case "add", "edit":
+		return true
+	case "mcp_manager":
+		return m.mcpMode == "add" || m.mcpMode == "edit" || m.mcpMode == "apps_toggle"
+	}
+	return false
+}
+
+// reloadFromDisk 重新读取 config.json 并刷新界面缓存
+// 仅 live 文件变化时无需重新加载，下次切换前会自动回填到当前配置
+func (m *Model) reloadFromDisk(changed []string) {
+	if !slices.Contains(changed, m.configPath) {
+		m.message = "↻ live 文件已被外部修改: " + displayPaths(changed) + "（切换时将回填到当前配置）"
+		m.err = nil
+		return
+	}
+
+	if err := m.manager.Load(); err != nil {
+		m.err = fmt.Errorf("重新加载配置失败: %w", err)
+		m.message = ""
+		return
+	}
+	m.refreshProviders()
+	if m.cursor >= len(m.providers) {
+		m.cursor = max(len(m.providers)-1, 0)
+	}
+	if m.viewMode == "multi" {
+		m.refreshAllColumns()
+	}
+	m.refreshMcpServers()
+	if m.mcpCursor >= len(m.mcpServers) {
+		m.mcpCursor = max(len(m.mcpServers)-1, 0)
+	}
+	m.message = "↻ 检测到外部修改，已重新加载配置"
+	m.err = nil
+}
+
+// handleReloadConflictKeys 处理编辑期间检测到外部修改时的冲突提示
+func (m Model) handleReloadConflictKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r", "R":
+		// 放弃编辑，回到列表并加载外部修改
+		changed := m.reloadConflict
+		m.reloadConflict = nil
+		if m.mode == "mcp_manager" {
+			m.mcpMode = "list"
+			m.selectedMcp = nil
+		} else {
+			m.mode = "list"
+			m.copyFromProvider = nil
+		}
+		m.reloadFromDisk(changed)
+	case "k", "K", "esc":
+		// 保留编辑：先加载外部修改，保存时仅覆盖正在编辑的条目
+		changed := m.reloadConflict
+		m.reloadConflict = nil
+		if slices.Contains(changed, m.configPath) {
+			if err := m.manager.Load(); err != nil {
+				m.err = fmt.Errorf("重新加载配置失败: %w", err)
+				m.message = ""
+				return m, nil
+			}
+		}
+		m.message = "已保留编辑内容，保存时将覆盖外部对该条目的修改"
+		m.err = nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// viewReloadConflict 渲染外部修改冲突提示
+func (m Model) viewReloadConflict() string {
+	var s strings.Builder
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#007AFF")).
+		Padding(0, 1).
+		Render(fmt.Sprintf("检测到外部修改 (v%s)", m.getVersion()))
+	s.WriteString(title + "\n\n")
+
+	s.WriteString("编辑期间以下文件被其他程序修改：\n")
+	for _, path := range m.reloadConflict {
+		s.WriteString("  • " + path + "\n")
+	}
+	s.WriteString("\n")
+
+	warning := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FF9500")).
+		Bold(true).
+		Render("⚠ 重新加载将丢弃当前未保存的编辑")
+	s.WriteString(warning + "\n\n")
+
+	reloadStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("#FF3B30")).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Padding(0, 2).
+		Bold(true)
+	keepStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("#8E8E93")).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Padding(0, 2)
+
+	s.WriteString(reloadStyle.Render("重新加载 (R)") + " ")
+	s.WriteString(keepStyle.Render("保留编辑 (K)"))
+
+	return s.String()
+}
+
+// displayPaths 以文件名列出变化的文件
+func displayPaths(paths []string) string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,192 @@
+package writeset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
+)
+
+// WriteSet 暂存一组文件修改，提交时整体生效
+// 提交前先把所有原文件记录到日志文件，任一步失败即恢复原文件；
+// 进程在提交过程中被中断时，下次启动可通过 Recover 根据日志恢复
+type WriteSet struct {
+	journalPath string
+	changes     []change
+}
+
+type change struct {
+	path   string
+	data   []byte
+	perm   os.FileMode
+	remove bool
+}
+
+// journal 日志文件内容，记录提交前每个文件的原始状态
+type journal struct {
+	Files []original `json:"files"`
+}
+
+type original struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Data    []byte      `json:"data,omitempty"`
+	Perm    os.FileMode `json:"perm,omitempty"`
+}
+
+// New 创建写入集合，journalPath 为提交期间使用的日志文件路径
+func New(journalPath string) *WriteSet {
+	return &WriteSet{journalPath: journalPath}
+}
+
+// Write 暂存文件写入，同一路径多次暂存时以最后一次为准
+func (w *WriteSet) Write(path string, data []byte, perm os.FileMode) {
+	w.stage(change{path: path, data: data, perm: perm})
+}
+
+// WriteJSON 暂存 JSON 文件写入（缩进格式，与 utils.WriteJSONFile 一致）
+func (w *WriteSet) WriteJSON(path string, v interface{}, perm os.FileMode) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化 JSON 失败: %w", err)
+	}
+	w.Write(path, data, perm)
+	return nil
+}
+
+// Remove 暂存文件删除，文件不存在时提交不报错
+func (w *WriteSet) Remove(path string) {
+	w.stage(change{path: path, remove: true})
+}
+
+func (w *WriteSet) stage(c change) {
+	for i := range w.changes {
+		if w.changes[i].path == c.path {
+			w.changes[i] = c
+			return
+		}
+	}
+	w.changes = append(w.changes, c)
+}
+
+// Len 返回暂存的文件数
+func (w *WriteSet) Len() int {
+	return len(w.changes)
+}
+
+// Commit 依次应用所有修改；任一步失败时恢复所有原文件并返回错误
+func (w *WriteSet) Commit() error {
+	if len(w.changes) == 0 {
+		return nil
+	}
+
+	var j journal
+	for _, c := range w.changes {
+		orig, err := snapshot(c.path)
+		if err != nil {
+			return err
+		}
+		j.Files = append(j.Files, orig)
+	}
+
+	// 日志中包含原文件内容（可能有 Token），仅当前用户可读
+	if err := os.MkdirAll(filepath.Dir(w.journalPath), 0755); err != nil {
+		return fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	if err := utils.WriteJSONFile(w.journalPath, j, 0600); err != nil {
+		return fmt.Errorf("写入切换日志失败: %w", err)
+	}
+
+	for _, c := range w.changes {
+		if err := apply(c); err != nil {
+			if rbErr := restore(j.Files); rbErr != nil {
+				// 保留日志，下次启动时再尝试恢复
+				return fmt.Errorf("%w; 回滚失败: %v", err, rbErr)
+			}
+			os.Remove(w.journalPath)
+			return fmt.Errorf("%w (已回滚所有修改)", err)
+		}
+	}
+
+	if err := os.Remove(w.journalPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除切换日志失败: %w", err)
+	}
+	return nil
+}
+
+// Recover 检查日志文件，存在时说明上次提交被中断，恢复日志中记录的原文件
+// 返回是否执行了恢复
+func Recover(journalPath string) (bool, error) {
+	data, err := os.ReadFile(journalPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("读取切换日志失败: %w", err)
+	}
+
+	var j journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		// 日志本身未写完整，说明尚未修改任何文件
+		os.Remove(journalPath)
+		return false, nil
+	}
+	if err := restore(j.Files); err != nil {
+		return false, err
+	}
+	if err := os.Remove(journalPath); err != nil {
+		return false, fmt.Errorf("删除切换日志失败: %w", err)
+	}
+	return true, nil
+}
+
+func snapshot(path string) (original, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return original{Path: path}, nil
+	}
+	if err != nil {
+		return original{}, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return original{}, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	return original{Path: path, Existed: true, Data: data, Perm: info.Mode().Perm()}, nil
+}
+
+func apply(c change) error {
+	if c.remove {
+		if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("删除 %s 失败: %w", c.path, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := utils.AtomicWriteFile(c.path, c.data, c.perm); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", c.path, err)
+	}
+	return nil
+}
+
+// restore 恢复原文件：原本存在的写回原内容，原本不存在的删除
+func restore(files []original) error {
+	var errs []error
+	for _, f := range files {
+		if !f.Existed {
+			if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("删除 %s 失败: %w", f.Path, err))
+			}
+			continue
+		}
+		if err := utils.AtomicWriteFile(f.Path, f.Data, f.Perm); err != nil {
+			errs = append(errs, fmt.Errorf("恢复 %s 失败: %w", f.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,106 @@
+package writeset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/YangQing-Lin/cc-switch-cli/internal/utils"
+)
+
+func TestCommitAppliesAllChanges(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "switch.journal")
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "sub", "b.toml")
+	gone := filepath.Join(dir, "gone")
+	os.WriteFile(gone, []byte("x"), 0644)
+
+	ws := New(journalPath)
+	ws.Write(a, []byte("first"), 0644)
+	ws.Write(a, []byte("A"), 0644)
+	ws.Write(b, []byte("B"), 0600)
+	ws.Remove(gone)
+	if ws.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", ws.Len())
+	}
+	if err := ws.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(a); string(data) != "A" {
+		t.Errorf("a = %q", data)
+	}
+	if data, _ := os.ReadFile(b); string(data) != "B" {
+		t.Errorf("b = %q", data)
+	}
+	if _, err := os.Stat(gone); !os.IsNotExist(err) {
+		t.Error("gone 应被删除")
+	}
+	if _, err := os.Stat(journalPath); !os.IsNotExist(err) {
+		t.Error("提交成功后应删除日志")
+	}
+}
+
+func TestCommitRollsBackOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "switch.journal")
+	a := filepath.Join(dir, "a.json")
+	created := filepath.Join(dir, "created.json")
+	os.WriteFile(a, []byte("original"), 0644)
+
+	// 父路径是普通文件，写入必然失败
+	blocker := filepath.Join(dir, "blocker")
+	os.WriteFile(blocker, []byte("file"), 0644)
+
+	ws := New(journalPath)
+	ws.Write(a, []byte("new"), 0644)
+	ws.Write(created, []byte("new"), 0644)
+	ws.Write(filepath.Join(blocker, "child.json"), []byte("x"), 0644)
+
+	if err := ws.Commit(); err == nil {
+		t.Fatal("Commit() 应返回错误")
+	}
+	if data, _ := os.ReadFile(a); string(data) != "original" {
+		t.Errorf("a 未回滚: %q", data)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("新建的文件应在回滚时删除")
+	}
+	if _, err := os.Stat(journalPath); !os.IsNotExist(err) {
+		t.Error("回滚成功后应删除日志")
+	}
+}
+
+func TestRecoverInterruptedCommit(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "switch.journal")
+	a := filepath.Join(dir, "a.json")
+	created := filepath.Join(dir, "created.json")
+	os.WriteFile(a, []byte("original"), 0644)
+
+	// 模拟写入日志后、修改文件过程中进程退出
+	origA, _ := snapshot(a)
+	origCreated, _ := snapshot(created)
+	if err := utils.WriteJSONFile(journalPath, journal{Files: []original{origA, origCreated}}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(a, []byte("half"), 0644)
+	os.WriteFile(created, []byte("half"), 0644)
+
+	recovered, err := Recover(journalPath)
+	if err != nil || !recovered {
+		t.Fatalf("Recover() = %v, %v", recovered, err)
+	}
+	if data, _ := os.ReadFile(a); string(data) != "original" {
+		t.Errorf("a 未恢复: %q", data)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("中断时新建的文件应被删除")
+	}
+
+	recovered, err = Recover(journalPath)
+	if err != nil || recovered {
+		t.Errorf("无日志时 Recover() = %v, %v", recovered, err)
+	}
+}